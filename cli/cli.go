@@ -4,6 +4,7 @@ package cli
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"sort"
@@ -302,6 +303,13 @@ func Parse() {
 		log.Debug("Checking subcommand", arg)
 		cmdNode, ok := parentNode.subcommands[arg]
 		if !ok {
+			// A command group with a handler of its own accepts any
+			// arguments that don't match one of its subcommands
+			if parentNode.cmd.Handler != nil {
+				log.Debug("Passing remaining arguments to", parentNode.commandChain())
+				index--
+				break
+			}
 			parentNode.invalidCommand(arg)
 		}
 
@@ -367,3 +375,68 @@ func checkShowUsage(node *Command, index int) {
 		node.Usage()
 	}
 }
+
+// ParseFlags parses the arguments using the given flag set. Unlike
+// flag.FlagSet.Parse, the flags may be interspersed with the positional
+// arguments, which are returned in order. Arguments that begin with - and a
+// digit, such as the relative date -3d, are positional rather than flags,
+// unless they are the value of a flag, and all arguments after -- are
+// positional.
+func ParseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) != 0 {
+		if isNegative(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+
+		// Only parse up to the next negative argument, since Parse would
+		// treat it as a flag
+		end := 1
+		for end < len(args) && !(isNegative(args[end]) && !needsValue(fs, args[end-1])) {
+			end++
+		}
+
+		err := fs.Parse(args[:end])
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse stops after --, leaving only positional arguments
+		consumed := end - fs.NArg()
+		if consumed != 0 && args[consumed-1] == "--" {
+			return append(positional, args[consumed:]...), nil
+		}
+
+		args = args[consumed:]
+		if len(args) != 0 && !isNegative(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+	}
+
+	return positional, nil
+}
+
+// isNegative returns true if the argument is a negative number or offset,
+// rather than a flag
+func isNegative(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9'
+}
+
+// needsValue returns true if the argument is a flag that takes its value
+// from the next argument
+func needsValue(fs *flag.FlagSet, arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || strings.Contains(arg, "=") {
+		return false
+	}
+
+	f := fs.Lookup(strings.TrimLeft(arg, "-"))
+	if f == nil {
+		return false
+	}
+
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !bf.IsBoolFlag()
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestParseFlags verifies that flags may be interspersed with positional
+// arguments, including negative relative dates
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args   string
+		pos    string
+		yes    bool
+		filter string
+		err    bool
+	}{
+		{"2 +1d", "2 +1d", false, "", false},
+		{"-y 2 3", "2 3", true, "", false},
+		{"2 -y 3", "2 3", true, "", false},
+		{"2 -1d", "2 -1d", false, "", false},
+		{"2 -y -1d", "2 -1d", true, "", false},
+		{"-1d -y 2", "-1d 2", true, "", false},
+		{"-filter -3d 2", "2", false, "-3d", false},
+		{"-filter=pri<3 -2w", "-2w", false, "pri<3", false},
+		{"-y -- -x 2", "-x 2", true, "", false},
+		{"2 -x", "", false, "", true},
+	}
+
+	for _, test := range tests {
+		var yes bool
+		var filter string
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.BoolVar(&yes, "y", false, "yes")
+		fs.StringVar(&filter, "filter", "", "filter")
+		fs.SetOutput(ioutil.Discard)
+
+		pos, err := ParseFlags(fs, strings.Fields(test.args))
+		if (err != nil) != test.err {
+			t.Errorf("Parsing '%v', expected error %v, got %v\n", test.args, test.err, err)
+			continue
+		}
+
+		if strings.Join(pos, " ") != test.pos || yes != test.yes || filter != test.filter {
+			t.Errorf("Parsing '%v', expected %q %v %q, got %q %v %q\n", test.args,
+				test.pos, test.yes, test.filter, strings.Join(pos, " "), yes, filter)
+		}
+	}
+}
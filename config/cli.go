@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/module"
+)
+
+func init() {
+	mod := module.Module{Name: "config"}
+
+	mod.Callbacks[module.BuildCommandTree] = buildCommandTree
+
+	module.RegisterModule(mod)
+}
+
+func buildCommandTree() error {
+	var cmd cli.Cmd
+	var err error
+	var configRoot *cli.Command
+	cmd = cli.Cmd{
+		Command:   "config",
+		Usage:     "...",
+		BriefHelp: "view and change settings",
+		LongHelp: `
+The Overlord settings allow you to customize the behavior of the other
+modules. Settings are saved in the overlord.conf file in the Overlord
+data directory, which may also be edited directly.
+`,
+	}
+
+	// Register the config command group at the root, we'll add additional
+	// subcommands afterwards.
+	configRoot, err = cli.RegisterCommandGroup(nil, cmd)
+	if err != nil {
+		return err
+	}
+
+	// config list
+	cmd = cli.Cmd{
+		Command:   "list",
+		Usage:     " ",
+		BriefHelp: "list all settings",
+		LongHelp:  "\nList all settings that have been changed from the defaults.\n",
+		Handler:   listHandler,
+		Args:      cli.None,
+	}
+
+	_, err = cli.RegisterCommand(configRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// config get <key>
+	cmd = cli.Cmd{
+		Command:   "get",
+		Usage:     "<key>",
+		BriefHelp: "display a setting",
+		LongHelp:  "\nDisplay the value of a setting.\n",
+		Handler:   getHandler,
+		Args:      cli.Exact,
+		Count:     1,
+	}
+
+	_, err = cli.RegisterCommand(configRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// config set <key> <value>
+	cmd = cli.Cmd{
+		Command:   "set",
+		Usage:     "<key> <value>",
+		BriefHelp: "change a setting",
+		LongHelp:  "\nChange the value of a setting.\n",
+		Handler:   setHandler,
+		Args:      cli.AtLeast,
+		Count:     2,
+	}
+
+	_, err = cli.RegisterCommand(configRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// config unset <key>
+	cmd = cli.Cmd{
+		Command:   "unset",
+		Usage:     "<key>",
+		BriefHelp: "reset a setting to the default",
+		LongHelp:  "\nReset a setting to its default value.\n",
+		Handler:   unsetHandler,
+		Args:      cli.Exact,
+		Count:     1,
+	}
+
+	_, err = cli.RegisterCommand(configRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func listHandler(cmd *cli.Command, args []string) error {
+	for _, key := range Keys() {
+		fmt.Printf("%v = %v\n", key, settings[key])
+	}
+
+	return nil
+}
+
+func getHandler(cmd *cli.Command, args []string) error {
+	value, ok := Get(args[1])
+	if !ok {
+		return fmt.Errorf("Setting %v is not set", args[1])
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func setHandler(cmd *cli.Command, args []string) error {
+	return Set(args[1], strings.Join(args[2:], " "))
+}
+
+func unsetHandler(cmd *cli.Command, args []string) error {
+	return Unset(args[1])
+}
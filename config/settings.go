@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nirenjan.org/overlord/log"
+)
+
+// The settings file lives in the data directory, and holds one setting per
+// line in the form "key = value". Blank lines and lines beginning with #
+// are ignored. Keys are namespaced by the module that uses them, for
+// example "task.open-subtasks".
+
+// settings caches the contents of the settings file
+var settings map[string]string
+
+func settingsFile() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "overlord.conf"), nil
+}
+
+// loadSettings reads the settings file, if it hasn't been read already
+func loadSettings() error {
+	if settings != nil {
+		return nil
+	}
+
+	path, err := settingsFile()
+	if err != nil {
+		return err
+	}
+
+	settings = make(map[string]string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+
+		i := strings.Index(text, "=")
+		if i < 0 {
+			log.Warning("Ignoring invalid setting on line", line, "of", path)
+			continue
+		}
+
+		key := strings.TrimSpace(text[:i])
+		settings[key] = strings.TrimSpace(text[i+1:])
+	}
+
+	return scanner.Err()
+}
+
+// saveSettings writes the settings back to the settings file. Any comments
+// in the file are not preserved.
+func saveSettings() error {
+	path, err := settingsFile()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, key := range Keys() {
+		file.WriteString(fmt.Sprintf("%v = %v\n", key, settings[key]))
+	}
+
+	return nil
+}
+
+// Get returns the value of the setting, and whether it has been set
+func Get(key string) (string, bool) {
+	err := loadSettings()
+	if err != nil {
+		log.Warning("Unable to load settings:", err)
+		return "", false
+	}
+
+	value, ok := settings[key]
+	return value, ok
+}
+
+// String returns the value of the setting, or def if it has not been set
+func String(key, def string) string {
+	value, ok := Get(key)
+	if !ok {
+		return def
+	}
+
+	return value
+}
+
+// Bool returns the value of a boolean setting, or def if it has not been set
+// or is not a valid boolean
+func Bool(key string, def bool) bool {
+	value, ok := Get(key)
+	if !ok {
+		return def
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warning("Setting", key, "is not a boolean, using", def)
+		return def
+	}
+
+	return b
+}
+
+// Float returns the value of a numeric setting, or def if it has not been
+// set or is not a valid number
+func Float(key string, def float64) float64 {
+	value, ok := Get(key)
+	if !ok {
+		return def
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Warning("Setting", key, "is not a number, using", def)
+		return def
+	}
+
+	return f
+}
+
+// Keys returns a sorted list of all the keys that have been set
+func Keys() []string {
+	err := loadSettings()
+	if err != nil {
+		log.Warning("Unable to load settings:", err)
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Set updates the value of the setting and saves it to the settings file
+func Set(key, value string) error {
+	err := loadSettings()
+	if err != nil {
+		return err
+	}
+
+	settings[key] = value
+	return saveSettings()
+}
+
+// Unset removes the setting from the settings file
+func Unset(key string) error {
+	err := loadSettings()
+	if err != nil {
+		return err
+	}
+
+	delete(settings, key)
+	return saveSettings()
+}
@@ -11,12 +11,26 @@ func init() {
 	mod.Callbacks[module.BuildCommandTree] = buildCommandTree
 	mod.Callbacks[module.ModuleInit] = journalInit
 
+	mod.Callbacks[module.Rebuild] = func() error {
+		db = make(map[string]DBEntry)
+		return BuildDb()
+	}
+
 	mod.DataCallbacks[module.Backup] = backupHandler
 	mod.DataCallbacks[module.Restore] = restoreHandler
 
 	module.RegisterModule(mod)
 }
 
+const dateHelp = `
+
+	-from date              Only include entries on or after the date
+	-to date                Only include entries on or before the date
+
+The dates may be given as a date such as 2026-11-05, or a relative date
+such as today, yesterday, monday, -3d or -2w.
+`
+
 func buildCommandTree() error {
 	var cmd cli.Cmd
 	var err error
@@ -56,12 +70,12 @@ contain the characters a-z, 0-9 and hyphen (-).
 		return err
 	}
 
-	// journal list [tag [tag ...]]
+	// journal list [-from date] [-to date] [tag [tag ...]]
 	cmd = cli.Cmd{
 		Command:   "list",
-		Usage:     "[tag [tag ...]]",
+		Usage:     "[-from date] [-to date] [tag [tag ...]]",
 		BriefHelp: "list all journal entries filtered by tags",
-		LongHelp:  "List all journal entries filtered by tags",
+		LongHelp:  "List all journal entries filtered by tags and dates" + dateHelp,
 		Handler:   listHandler,
 		Args:      cli.Any,
 	}
@@ -71,12 +85,12 @@ contain the characters a-z, 0-9 and hyphen (-).
 		return err
 	}
 
-	// journal display [tag [tag ...]]
+	// journal display [-from date] [-to date] [tag [tag ...]]
 	cmd = cli.Cmd{
 		Command:   "display",
-		Usage:     "[tag [tag ...]]",
+		Usage:     "[-from date] [-to date] [tag [tag ...]]",
 		BriefHelp: "display all journal entries filtered by tags",
-		LongHelp:  "Display all journal entries filtered by tags",
+		LongHelp:  "Display all journal entries filtered by tags and dates" + dateHelp,
 		Handler:   displayHandler,
 		Args:      cli.Any,
 	}
@@ -2,14 +2,18 @@ package journal
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"nirenjan.org/overlord/cli"
 	"nirenjan.org/overlord/config"
 	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/undo"
 	"nirenjan.org/overlord/util"
 )
 
@@ -50,14 +54,69 @@ func newHandler(cmd *cli.Command, args []string) error {
 	return SaveDb()
 }
 
+// listFilter holds the tags and date range used to filter the entries
+type listFilter struct {
+	tags []string
+	from time.Time
+	to   time.Time
+}
+
+// parseListFilter parses the date range options and tags from the arguments
+func parseListFilter(args []string) (listFilter, error) {
+	var filter listFilter
+	var from, to string
+
+	fs := flag.NewFlagSet("overlord journal", flag.ContinueOnError)
+	fs.StringVar(&from, "from", "", "start date")
+	fs.StringVar(&to, "to", "", "end date")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return filter, err
+	}
+	filter.tags = fs.Args()
+
+	now := time.Now()
+	if from != "" {
+		filter.from, _, err = util.ParseDate(from, now)
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	if to != "" {
+		var hasTime bool
+		filter.to, hasTime, err = util.ParseDate(to, now)
+		if err != nil {
+			return filter, err
+		}
+
+		// Include the entire day
+		if !hasTime {
+			filter.to = util.EndOfDay(filter.to)
+		}
+	}
+
+	return filter, nil
+}
+
 // buildEntryList generates a sorted list of entries based on the given filter
-func buildEntryList(filter []string) []string {
+func buildEntryList(filter listFilter) []string {
 	var list = make([]string, len(db))
 	i := 0
 	for id, entry := range db {
 		useEntry := true
-		if len(filter) > 0 {
-			useEntry = util.TagsIntersection(filter, entry.Tags)
+		if len(filter.tags) > 0 {
+			useEntry = util.TagsIntersection(filter.tags, entry.Tags)
+		}
+		if !filter.from.IsZero() && entry.Date.Before(filter.from) {
+			useEntry = false
+		}
+		if !filter.to.IsZero() && entry.Date.After(filter.to) {
+			useEntry = false
 		}
 
 		if useEntry {
@@ -75,9 +134,11 @@ func buildEntryList(filter []string) []string {
 
 // listHandler lists all entries with the given tag
 func listHandler(cmd *cli.Command, args []string) error {
-	filter := args[1:]
+	filter, err := parseListFilter(args[1:])
+	if err != nil {
+		return err
+	}
 
-	var err error
 	err = LoadDb()
 	if err != nil {
 		return err
@@ -106,9 +167,11 @@ func listHandler(cmd *cli.Command, args []string) error {
 
 // displayHandler displays all entries with the given tag
 func displayHandler(cmd *cli.Command, args []string) error {
-	filter := args[1:]
+	filter, err := parseListFilter(args[1:])
+	if err != nil {
+		return err
+	}
 
-	var err error
 	err = LoadDb()
 	if err != nil {
 		return err
@@ -133,18 +196,29 @@ func displayHandler(cmd *cli.Command, args []string) error {
 }
 
 func getEntryByIdSuffix(entryID string) (Entry, error) {
-	for id, dbEntry := range db {
-		if strings.HasSuffix(id, entryID) {
-			entry, err := entryFromFile(dbEntry.Path)
-			if err != nil {
-				return Entry{}, err
-			}
+	var matches []string
+	for id := range db {
+		if id == entryID {
+			matches = []string{id}
+			break
+		}
 
-			return entry, nil
+		if strings.HasSuffix(id, entryID) {
+			matches = append(matches, id)
 		}
 	}
 
-	return Entry{}, errors.New("Entry not found")
+	switch len(matches) {
+	case 0:
+		return Entry{}, errors.New("Entry not found")
+
+	case 1:
+		return entryFromFile(db[matches[0]].Path)
+	}
+
+	sort.Strings(matches)
+	return Entry{}, fmt.Errorf("Ambiguous ID %v, candidates: %v", entryID,
+		strings.Join(matches, ", "))
 }
 
 // showHandler shows the entry with the given ID
@@ -235,6 +309,11 @@ func deleteHandler(cmd *cli.Command, args []string) error {
 		return err
 	}
 
+	err = undo.Record(entry.Path)
+	if err != nil {
+		return err
+	}
+
 	// Delete the database entry
 	DeleteDbEntry(entry)
 	os.Remove(entry.Path)
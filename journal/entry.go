@@ -14,6 +14,7 @@ import (
 
 	"nirenjan.org/overlord/config"
 	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/undo"
 	"nirenjan.org/overlord/util"
 )
 
@@ -97,6 +98,11 @@ func (e *Entry) UpdatePath() error {
 }
 
 func (e *Entry) Write() error {
+	err := undo.Record(e.Path)
+	if err != nil {
+		return err
+	}
+
 	efile, err := os.Create(e.Path)
 	if err != nil {
 		return err
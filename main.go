@@ -5,9 +5,11 @@ import (
 
 	// Overlord modules
 	_ "nirenjan.org/overlord/backup"
+	_ "nirenjan.org/overlord/config"
 	_ "nirenjan.org/overlord/init"
 	_ "nirenjan.org/overlord/journal"
 	_ "nirenjan.org/overlord/task"
+	_ "nirenjan.org/overlord/undo"
 	_ "nirenjan.org/overlord/version"
 )
 
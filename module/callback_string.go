@@ -12,12 +12,13 @@ func _() {
 	_ = x[ModuleInit-1]
 	_ = x[Backup-2]
 	_ = x[Restore-3]
-	_ = x[maxCallbacks-4]
+	_ = x[Rebuild-4]
+	_ = x[maxCallbacks-5]
 }
 
-const _Callback_name = "BuildCommandTreeModuleInitBackupRestoremaxCallbacks"
+const _Callback_name = "BuildCommandTreeModuleInitBackupRestoreRebuildmaxCallbacks"
 
-var _Callback_index = [...]uint8{0, 16, 26, 32, 39, 51}
+var _Callback_index = [...]uint8{0, 16, 26, 32, 39, 46, 58}
 
 func (i Callback) String() string {
 	if i < 0 || i >= Callback(len(_Callback_index)-1) {
@@ -12,6 +12,7 @@ const (
 	ModuleInit
 	Backup
 	Restore
+	Rebuild
 	maxCallbacks
 )
 
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+)
+
+// Annotation is a timestamped comment on a task
+type Annotation struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+func registerAnnotateHandler(root *cli.Command) error {
+	// task annotate
+	cmd := cli.Cmd{
+		Command:   "annotate",
+		Usage:     "<id> <text>",
+		BriefHelp: "add a timestamped annotation to a task",
+		LongHelp: `
+Add a timestamped annotation to a task, for example to record why the
+task was blocked. Unlike the notes, annotations are added directly from
+the command line, and are shown in the order they were added.
+`,
+		Handler: annotateHandler,
+		Args:    cli.AtLeast,
+		Count:   2,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+func annotateHandler(cmd *cli.Command, args []string) error {
+	text := strings.TrimSpace(strings.Join(args[2:], " "))
+	if len(text) == 0 {
+		return fmt.Errorf("Missing annotation text")
+	}
+
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(args[1])
+	if err != nil {
+		return err
+	}
+
+	task.Annotations = append(task.Annotations, Annotation{
+		Time: time.Now(),
+		Text: text,
+	})
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+// parseAnnotation parses an annotation from the task file, in the form
+// "<time> <text>"
+func parseAnnotation(value string) (Annotation, error) {
+	var a Annotation
+	var err error
+
+	fields := strings.SplitN(value, " ", 2)
+	if len(fields) != 2 {
+		return a, fmt.Errorf("Invalid annotation '%v'", value)
+	}
+
+	a.Time, err = time.ParseInLocation(time.RFC3339, fields[0], time.Local)
+	a.Text = fields[1]
+	return a, err
+}
@@ -0,0 +1,272 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/undo"
+)
+
+// Archived tasks are moved out of the task directory into the archive
+// directory, under archive/task/<year>, keeping the same file name. They
+// are no longer part of the task database, but may be restored later.
+
+// archiveDir returns the archive directory for tasks created in the year
+func archiveDir(year string) (string, error) {
+	return config.ModuleDir("archive", "task", year)
+}
+
+// walkArchive runs the callback for every archived task file
+func walkArchive(callback func(path string) error) error {
+	dir, err := config.ModuleDir("archive", "task")
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".task") {
+			return nil
+		}
+
+		return callback(path)
+	})
+}
+
+// moveFile moves the file, recording both paths in the undo log
+func moveFile(src, dst string) error {
+	err := undo.Record(src)
+	if err != nil {
+		return err
+	}
+
+	err = undo.Record(dst)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+// archiveTask moves the task file into the archive
+func archiveTask(task *Task) error {
+	dir, err := archiveDir(task.Created.Format("2006"))
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, filepath.Base(task.Path))
+	err = moveFile(task.Path, path)
+	if err != nil {
+		return err
+	}
+
+	task.Path = path
+	task.Archived = true
+	return nil
+}
+
+// restoreTask moves an archived task file back into the task directory
+func restoreTask(task *Task) error {
+	src := task.Path
+	err := task.UpdatePath()
+	if err != nil {
+		return err
+	}
+
+	task.Archived = false
+	return moveFile(src, task.Path)
+}
+
+// readArchive returns all of the archived tasks, in the default sort order
+func readArchive() (TaskList, error) {
+	var tasks TaskList
+	err := walkArchive(func(path string) error {
+		task, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		task.Archived = true
+		tasks = append(tasks, task)
+		return nil
+	})
+
+	sort.Sort(tasks)
+	return tasks, err
+}
+
+// reportTasks returns the tasks in the database along with the archived
+// tasks, so that reports on past work still cover the tasks that have been
+// cleaned up
+func reportTasks() (TaskList, error) {
+	err := LoadDb()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := readArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range DB {
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// findArchived returns the archived task with the given ID, a unique prefix
+// of the ID, or its number in the working set
+func findArchived(id string) (Task, error) {
+	tasks, err := readArchive()
+	if err != nil {
+		return Task{}, err
+	}
+
+	// Archived tasks may be listed with "task list archived", which
+	// replaces the working set
+	prefix := strings.ToLower(id)
+	if n, err := strconv.Atoi(id); err == nil && n > 0 {
+		ws := loadWorkingSet()
+		if n <= len(ws) {
+			prefix = ws[n-1]
+		}
+	}
+
+	var matches []Task
+	for _, task := range tasks {
+		if task.ID == prefix {
+			return task, nil
+		}
+		if strings.HasPrefix(task.ID, prefix) && prefix != "" {
+			matches = append(matches, task)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Task{}, fmt.Errorf("Task %v not found", id)
+
+	case 1:
+		return matches[0], nil
+	}
+
+	var candidates []string
+	for _, task := range matches {
+		candidates = append(candidates, task.ID)
+	}
+	sort.Strings(candidates)
+	return Task{}, ambiguousIDError{id, candidates}
+}
+
+func registerArchiveHandler(root *cli.Command) error {
+	// task archive
+	cmd := cli.Cmd{
+		Command:   "archive",
+		Usage:     "...",
+		BriefHelp: "search archived tasks",
+		LongHelp: `
+Search the tasks that have been moved to the archive by "task cleanup".
+`,
+		Subcommand: "Archive Commands",
+	}
+
+	archiveRoot, err := cli.RegisterCommandGroup(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task archive search
+	cmd = cli.Cmd{
+		Command:   "search",
+		Usage:     "[-project name] [-tag tag,...] [-from date] [-to date] [text ...]",
+		BriefHelp: "search the archived tasks",
+		LongHelp: `
+Search the archived tasks for the given text, in the description, notes
+and annotations of each task. If more than one word is given, the tasks
+must contain all of them. This command accepts the following options
+
+	-project name           Only search tasks in the given project
+	-tag tag,...            Only search tasks with any of the given tags
+	-from date              Only search tasks closed on or after the date
+	-to date                Only search tasks closed on or before the date
+
+For example, "task archive search -from -6m -project web" lists the web
+tasks that were completed or deleted in the last six months.
+`,
+		Handler: archiveSearchHandler,
+		Args:    cli.Any,
+	}
+
+	_, err = cli.RegisterCommand(archiveRoot, cmd)
+	return err
+}
+
+// containsText returns true if the description, notes or annotations of the
+// task contain all of the words, ignoring case
+func (t *Task) containsText(words []string) bool {
+	text := []string{t.Description, t.Notes}
+	for _, a := range t.Annotations {
+		text = append(text, a.Text)
+	}
+	all := strings.ToLower(strings.Join(text, "\n"))
+
+	for _, word := range words {
+		if !strings.Contains(all, strings.ToLower(word)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func archiveSearchHandler(cmd *cli.Command, args []string) error {
+	var project, from, to string
+	var tags tagList
+
+	fs := flag.NewFlagSet("overlord task archive search", flag.ContinueOnError)
+	fs.StringVar(&project, "project", "", "project name")
+	fs.Var(&tags, "tag", "comma separated tags")
+	fs.StringVar(&from, "from", "", "start date")
+	fs.StringVar(&to, "to", "", "end date")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	words, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	var start, end time.Time
+	start, end, err = parseReportRange(from, to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var tasks TaskList
+	tasks, err = readArchive()
+	if err != nil {
+		return err
+	}
+
+	return showTaskList(tasks, func(t *Task) bool {
+		closed := t.closedAt()
+		return !closed.Before(start) && closed.Before(end) &&
+			t.InProject(project) && t.HasTags(tags) && t.containsText(words)
+	})
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"encoding/json"
+	"path/filepath"
 
 	"nirenjan.org/overlord/util"
 )
@@ -25,6 +26,14 @@ func backupHandler(_ []byte) ([]byte, error) {
 		return dummy, err
 	}
 
+	// Include the archived tasks, so that they are not lost
+	var archived TaskList
+	archived, err = readArchive()
+	if err != nil {
+		return dummy, err
+	}
+	tasks = append(tasks, archived...)
+
 	return json.Marshal(tasks)
 }
 
@@ -44,6 +53,20 @@ func restoreHandler(data []byte) ([]byte, error) {
 			return dummy, err
 		}
 		task.UpdateID()
+
+		// Archived tasks go back into the archive, rather than the
+		// database
+		if task.Archived {
+			var dir string
+			dir, err = archiveDir(task.Created.Format("2006"))
+			if err != nil {
+				return dummy, err
+			}
+			task.Path = filepath.Join(dir, filepath.Base(task.Path))
+			task.Write()
+			continue
+		}
+
 		task.Write()
 		AddDbEntry(task)
 	}
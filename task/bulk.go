@@ -0,0 +1,156 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/util"
+)
+
+// bulkHelp describes the options for commands that act on several tasks
+const bulkHelp = `
+More than one task ID may be given, and the tasks may also be selected
+with a filter expression (see "task list -h"). This command accepts the
+following options
+
+	-filter expr            Also act on the tasks matching the filter
+	-y                      Don't ask for confirmation before acting on
+	                        more tasks than the task.confirm-threshold
+	                        setting (default 5)
+
+The options may be given before or after the task IDs. Arguments such as
+-3d are taken as relative dates rather than options, and any arguments
+after -- are never taken as options.
+`
+
+// bulkOptions holds the options used to select the tasks for commands that
+// act on several tasks
+type bulkOptions struct {
+	filter string
+	yes    bool
+}
+
+// addFlags adds the bulk options to the flag set
+func (b *bulkOptions) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&b.filter, "filter", "", "filter expression")
+	fs.BoolVar(&b.yes, "y", false, "don't ask for confirmation")
+}
+
+// selectTasks returns the IDs of the tasks selected by the leading task
+// IDs in the arguments and the filter, along with the remaining arguments.
+// At least keep arguments are left for the command itself. The database
+// must already be loaded.
+func (b *bulkOptions) selectTasks(args []string, keep int) ([]string, []string, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for len(args) > keep {
+		id, err := resolveID(args[0])
+		if err != nil {
+			if _, ok := err.(ambiguousIDError); ok {
+				return nil, nil, err
+			}
+			break
+		}
+		add(id)
+		args = args[1:]
+	}
+
+	if b.filter != "" {
+		q, err := parseQuery(b.filter)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var matched []string
+		for id, task := range DB {
+			task := task
+			if q(&task) {
+				matched = append(matched, id)
+			}
+		}
+
+		sort.Strings(matched)
+		for _, id := range matched {
+			add(id)
+		}
+
+		if len(matched) == 0 && len(ids) == 0 {
+			return nil, nil, fmt.Errorf("No tasks match the filter '%v'", b.filter)
+		}
+	}
+
+	if len(ids) == 0 {
+		if len(args) != 0 {
+			return nil, nil, fmt.Errorf("Task %v not found", args[0])
+		}
+		return nil, nil, fmt.Errorf("No tasks given")
+	}
+
+	return ids, args, nil
+}
+
+// apply runs the action on each of the selected tasks, after asking for
+// confirmation if there are too many of them. If there is a single task,
+// then any error is returned as is. Otherwise, the result for each task is
+// shown, and an error is returned if the action failed on any of them.
+func (b *bulkOptions) apply(verb string, ids []string, action func(task *Task) error) error {
+	threshold := config.Float("task.confirm-threshold", 5)
+	if !b.yes && float64(len(ids)) > threshold {
+		if !util.Confirm(fmt.Sprintf("%v %v tasks?", verb, len(ids))) {
+			return fmt.Errorf("Cancelled")
+		}
+	}
+
+	var failed int
+	for _, id := range ids {
+		task, err := getTask(id)
+		if err == nil {
+			err = action(&task)
+		}
+
+		if len(ids) == 1 {
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		if err != nil {
+			failed++
+			fmt.Printf("%-12v failed: %v\n", id, err)
+		} else {
+			fmt.Printf("%-12v ok:     %v\n", id, task.Description)
+		}
+	}
+
+	err := SaveDb()
+	if err != nil {
+		return err
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%v of %v tasks failed", failed, len(ids))
+	}
+
+	return nil
+}
+
+// saveTask writes the task to its file and updates the database entry
+func saveTask(task *Task) error {
+	err := task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(*task)
+	return nil
+}
@@ -0,0 +1,260 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+// Height of the burndown chart in lines
+const burndownHeight = 15
+
+func registerBurndownHandler(root *cli.Command) error {
+	// task burndown
+	cmd := cli.Cmd{
+		Command:   "burndown",
+		Usage:     "[-project name] [-from date] [-to date] [-estimate]",
+		BriefHelp: "show a burndown chart",
+		LongHelp: `
+Show a chart of the number of open tasks at the end of each day, sized to
+fit the width of the terminal. The dotted line shows the ideal burndown
+from the start of the range to no open tasks at the end. Tasks that have
+since been archived are still counted on the days they were open. This
+command accepts the following options
+
+	-project name           Only include tasks in the project
+	-from date              Start of the chart (default 2 weeks ago)
+	-to date                End of the chart (default today)
+	-estimate               Chart the remaining estimated time in hours,
+	                        rather than the number of open tasks
+`,
+		Handler: burndownHandler,
+		Args:    cli.Any,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+// closedAt returns the time that the task was completed or deleted, or the
+// zero time if the task is still open. Tasks that were closed before the
+// history was recorded are treated as closed when they were created.
+func (t *Task) closedAt() time.Time {
+	if t.State < Completed {
+		return time.Time{}
+	}
+
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].To >= Completed {
+			return t.History[i].Time
+		}
+	}
+
+	return t.Created
+}
+
+// openAt returns true if the task was open at the given time
+func (t *Task) openAt(at time.Time) bool {
+	if t.Created.After(at) {
+		return false
+	}
+
+	closed := t.closedAt()
+	return closed.IsZero() || closed.After(at)
+}
+
+// workedBy returns the time recorded in the work sessions of the task up to
+// the given time
+func (t *Task) workedBy(at time.Time) time.Duration {
+	var worked time.Duration
+	for _, s := range t.allSessions(time.Now()) {
+		if s.Stop.After(at) {
+			s.Stop = at
+		}
+		if s.Stop.After(s.Start) {
+			worked += s.Stop.Sub(s.Start)
+		}
+	}
+
+	return worked
+}
+
+// burndown returns the number of open tasks, or the remaining estimate in
+// hours, at the end of each day in the range
+func burndown(tasks TaskList, start, end time.Time, project string, estimate bool) []float64 {
+	var values []float64
+	now := time.Now()
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		at := day.AddDate(0, 0, 1)
+		if at.After(now) {
+			at = now
+		}
+
+		var value float64
+		for _, task := range tasks {
+			if !task.InProject(project) || !task.openAt(at) {
+				continue
+			}
+
+			if !estimate {
+				value++
+			} else if remaining := task.Estimate - task.workedBy(at); remaining > 0 {
+				value += remaining.Hours()
+			}
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// showBurndown draws the burndown chart, with one column for each value,
+// or for evenly spaced values if there are too many to fit the terminal
+func showBurndown(out io.Writer, values []float64, start, end time.Time) {
+	cols := terminal.Width() - 10
+	if cols > len(values) {
+		cols = len(values)
+	}
+	if cols < 1 {
+		cols = 1
+	}
+
+	sampled := make([]float64, cols)
+	var max float64
+	for i := range sampled {
+		sampled[i] = values[(i+1)*len(values)/cols-1]
+		if sampled[i] > max {
+			max = sampled[i]
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	// Spread the days over the width of the terminal if there's room
+	width := (terminal.Width() - 10) / cols
+	if width < 1 {
+		width = 1
+	}
+	if width > 4 {
+		width = 4
+	}
+
+	bars := []rune(" ▁▂▃▄▅▆▇█")
+	for row := burndownHeight - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case burndownHeight - 1:
+			label = fmt.Sprintf("%.4g", max)
+		case burndownHeight / 2:
+			label = fmt.Sprintf("%.4g", max/2)
+		case 0:
+			label = "0"
+		}
+
+		var line strings.Builder
+		line.WriteString(fmt.Sprintf("%7v |", label))
+		for i, v := range sampled {
+			// Height of the bar in eighths of a line, relative to this row
+			eighths := int(v/max*burndownHeight*8+0.5) - row*8
+			cell := bars[0]
+			if eighths >= 8 {
+				cell = bars[8]
+			} else if eighths > 0 {
+				cell = bars[eighths]
+			}
+
+			// Mark the ideal burndown from the first value to zero
+			if cell == ' ' {
+				ideal := sampled[0]
+				if cols > 1 {
+					ideal = sampled[0] * float64(cols-1-i) / float64(cols-1)
+				}
+				if int(ideal/max*burndownHeight) == row && ideal > 0 {
+					cell = '·'
+				}
+			}
+
+			line.WriteString(strings.Repeat(string(cell), width))
+		}
+		fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+	}
+
+	fmt.Fprintf(out, "%7v +%v\n", "", strings.Repeat("-", cols*width))
+
+	first := start.Format("Jan 2")
+	last := end.AddDate(0, 0, -1).Format("Jan 2")
+	gap := cols*width - len(first) - len(last)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(out, "%7v  %v%v%v\n", "", first, strings.Repeat(" ", gap), last)
+}
+
+func burndownHandler(cmd *cli.Command, args []string) error {
+	var project, from, to string
+	var estimate bool
+
+	fs := flag.NewFlagSet("overlord task burndown", flag.ContinueOnError)
+	fs.StringVar(&project, "project", "", "project name")
+	fs.StringVar(&from, "from", "-13d", "start date")
+	fs.StringVar(&to, "to", "today", "end date")
+	fs.BoolVar(&estimate, "estimate", false, "remaining estimate")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		cmd.Usage()
+	}
+
+	now := time.Now()
+	var start, end time.Time
+	start, end, err = parseReportRange(from, to, now)
+	if err != nil {
+		return err
+	}
+
+	// The chart is drawn for whole days
+	start = periodStart(start, "day")
+	if !end.Equal(periodStart(end, "day")) {
+		end = periodStart(end, "day").AddDate(0, 0, 1)
+	}
+
+	var tasks TaskList
+	tasks, err = reportTasks()
+	if err != nil {
+		return err
+	}
+
+	values := burndown(tasks, start, end, project, estimate)
+
+	out := util.NewPager()
+	title := "Open tasks"
+	if estimate {
+		title = "Remaining estimate (hours)"
+	}
+	if project != "" {
+		title += " in " + project
+	}
+	fmt.Fprintln(out, title)
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	showBurndown(out, values, start, end)
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	out.Show()
+
+	return nil
+}
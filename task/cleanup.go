@@ -1,9 +1,15 @@
 package task
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/undo"
 	"nirenjan.org/overlord/util"
 )
 
@@ -11,15 +17,26 @@ func registerCleanupHandler(root *cli.Command) error {
 	// task cleanup
 	cmd := cli.Cmd{
 		Command:   "cleanup",
-		Usage:     " ",
+		Usage:     "[-older-than age] [-dry-run] [-delete]",
 		BriefHelp: "cleanup completed and deleted tasks",
 		LongHelp: `
-Delete all completed and deleted tasks from the database. This will
-actually remove them, and they will no longer show up in the task
-list.
+Move all completed and deleted tasks from the database into the archive.
+They will no longer show up in the task list, but may be listed with
+"task list archived", searched with "task archive search", and restored
+with "task reopen". This command accepts the following options
+
+	-older-than age         Only cleanup tasks that were completed or
+	                        deleted more than the given age ago, such as
+	                        30d, 2w or 6m
+	-dry-run                List the tasks that would be cleaned up,
+	                        without changing anything
+	-delete                 Permanently remove the tasks instead of
+	                        archiving them
+	-archive                Archive the tasks. This is the default, and
+	                        is only accepted for compatibility
 `,
 		Handler: cleanupHandler,
-		Args:    cli.None,
+		Args:    cli.Any,
 	}
 
 	_, err := cli.RegisterCommand(root, cmd)
@@ -30,17 +47,86 @@ list.
 	return nil
 }
 
+// parseAge returns the time the given age before now, for ages such as 30d
+func parseAge(age string, now time.Time) (time.Time, error) {
+	if age == "" {
+		return now, nil
+	}
+
+	if strings.HasPrefix(age, "-") || strings.HasPrefix(age, "+") {
+		return now, fmt.Errorf("Invalid age '%v'", age)
+	}
+
+	cutoff, _, err := util.ParseDate("-"+age, now)
+	if err != nil {
+		return now, fmt.Errorf("Invalid age '%v'", age)
+	}
+
+	return cutoff, nil
+}
+
 func cleanupHandler(cmd *cli.Command, args []string) error {
-	err := util.FileWalk("task", ".task", func(path string) error {
+	var remove, dryRun, archive bool
+	var age string
+
+	fs := flag.NewFlagSet("overlord task cleanup", flag.ContinueOnError)
+	fs.BoolVar(&remove, "delete", false, "delete tasks")
+	fs.BoolVar(&dryRun, "dry-run", false, "list tasks only")
+	fs.StringVar(&age, "older-than", "", "minimum age")
+	fs.BoolVar(&archive, "archive", false, "archive tasks")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		cmd.Usage()
+	}
+	if remove && archive {
+		return fmt.Errorf("Cannot both archive and delete tasks")
+	}
+
+	var cutoff time.Time
+	cutoff, err = parseAge(age, time.Now())
+	if err != nil {
+		return err
+	}
+
+	verb := "Archived"
+	if remove {
+		verb = "Deleted"
+	}
+	if dryRun {
+		verb = "Would cleanup"
+	}
+
+	err = util.FileWalk("task", ".task", func(path string) error {
 		// Load task from file
 		task, err1 := ReadFile(path)
 		if err1 != nil {
 			return err1
 		}
 
-		// If the task is marked as Completed or Deleted, then
-		// remove it from the disk, otherwise, add it to the database
-		if task.State == Completed || task.State == Deleted {
+		// If the task was marked as Completed or Deleted before the
+		// cutoff, then archive or remove it, otherwise, add it to the
+		// database
+		if task.State >= Completed && task.closedAt().Before(cutoff) {
+			fmt.Println(verb, task.ID, task.Description)
+			if dryRun {
+				return nil
+			}
+
+			if !remove {
+				return archiveTask(&task)
+			}
+
+			err1 = undo.Record(task.Path)
+			if err1 != nil {
+				return err1
+			}
 			return os.Remove(task.Path)
 		}
 
@@ -50,7 +136,7 @@ func cleanupHandler(cmd *cli.Command, args []string) error {
 		return nil
 	})
 
-	if err != nil {
+	if err != nil || dryRun {
 		return err
 	}
 
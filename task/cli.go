@@ -10,6 +10,11 @@ func init() {
 
 	mod.Callbacks[module.BuildCommandTree] = buildCommandTree
 
+	mod.Callbacks[module.Rebuild] = func() error {
+		DB = make(map[string]Task)
+		return BuildDb()
+	}
+
 	mod.DataCallbacks[module.Backup] = backupHandler
 	mod.DataCallbacks[module.Restore] = restoreHandler
 
@@ -47,11 +52,21 @@ need to be done, update the task state, due date, and priority.
 		return err
 	}
 
+	err = registerFilterHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
 	err = registerShowHandler(taskRoot)
 	if err != nil {
 		return err
 	}
 
+	err = registerNextHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
 	err = registerStateTransitionHandler(taskRoot)
 	if err != nil {
 		return err
@@ -62,10 +77,70 @@ need to be done, update the task state, due date, and priority.
 		return err
 	}
 
+	err = registerSubtaskHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerDependHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerSessionHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerReportHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerSwitchHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerFocusHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerAnnotateHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerStatsHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerBurndownHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerReopenHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
 	err = registerCleanupHandler(taskRoot)
 	if err != nil {
 		return err
 	}
 
+	err = registerArchiveHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
+	err = registerTemplateHandler(taskRoot)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -4,10 +4,15 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/log"
+	"nirenjan.org/overlord/util"
 )
 
 type State int
@@ -50,9 +55,56 @@ type Task struct {
 	Notes       string        `json:"notes,omitempty"`
 	Started     time.Time     `json:"started,omitempty"`
 	Worked      time.Duration `json:"worked,omitempty"`
+	Repeat      Recurrence    `json:"repeat,omitempty"`
+	Depends     []string      `json:"depends,omitempty"`
+	Subtasks    []Subtask     `json:"subtasks,omitempty"`
+	Project     string        `json:"project,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Wait        time.Time     `json:"wait,omitempty"`
+	Sessions    []Session     `json:"sessions,omitempty"`
+	Focus       int           `json:"focus,omitempty"`
+	Estimate    time.Duration `json:"estimate,omitempty"`
+	Annotations []Annotation  `json:"annotations,omitempty"`
+	History     []Transition  `json:"history,omitempty"`
+	Archived    bool          `json:"archived,omitempty"`
 	Path        string        `json:"-"`
 }
 
+// parseTags splits each of the arguments on commas, and returns the list of
+// non-empty tags. Tags are stored separated by spaces, so they may not
+// contain any spaces themselves.
+func parseTags(args []string) ([]string, error) {
+	var tags []string
+	for _, arg := range args {
+		for _, tag := range strings.Split(arg, ",") {
+			tag = strings.TrimSpace(tag)
+			if strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+				return nil, fmt.Errorf("Tag '%v' may not contain spaces", tag)
+			}
+			if len(tag) != 0 {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags, nil
+}
+
+// parseDue parses the due date for a task, see util.ParseDate for the
+// accepted formats. Dates without a time of day are due at the end of the day.
+func parseDue(s string) (time.Time, error) {
+	due, hasTime, err := util.ParseDate(s, time.Now())
+	if err != nil {
+		return due, err
+	}
+
+	if !hasTime {
+		due = util.EndOfDay(due)
+	}
+
+	return due, nil
+}
+
 func parsePriority(prio string) (priority int, err error) {
 	priority, err = strconv.Atoi(prio)
 	if err != nil || priority < 0 || priority > 9 {
@@ -69,7 +121,10 @@ func parsePriority(prio string) (priority int, err error) {
 // Deferred -> InProgress, Blocked, Deleted
 // Completed -> _
 // Deleted -> _
-func (t *Task) stateTransition(newState State) error {
+//
+// Each transition is recorded in the task history, along with the reason
+// for the change, if any.
+func (t *Task) stateTransition(newState State, reason string) error {
 	var allowed bool
 	switch t.State {
 	case Assigned:
@@ -101,17 +156,86 @@ func (t *Task) stateTransition(newState State) error {
 		return fmt.Errorf("Cannot transition task from %v to %v", t.State, newState)
 	}
 
+	if newState == Completed {
+		if done, total := t.subtaskProgress(); done != total {
+			if config.String("task.open-subtasks", "warn") == "refuse" {
+				return fmt.Errorf("Cannot complete task, %v of %v checklist items are still open",
+					total-done, total)
+			}
+			log.Warning("Completing task with", total-done, "open checklist items")
+		}
+	}
+
 	if newState == InProgress {
-		t.Started = time.Now()
+		if open := t.openDependencies(); len(open) != 0 {
+			return fmt.Errorf("Cannot start task, waiting on %v",
+				strings.Join(open, ", "))
+		}
+
+		if config.Bool("task.single-active", false) {
+			if active := t.otherActiveTasks(); len(active) != 0 {
+				return fmt.Errorf("Cannot start task, %v already in progress, use \"task switch\"",
+					strings.Join(active, ", "))
+			}
+		}
+	}
+
+	now := time.Now()
+	if newState == InProgress {
+		t.Started = now
 	} else if t.State == InProgress {
-		t.Worked += time.Since(t.Started)
+		t.Worked += now.Sub(t.Started)
+		t.Sessions = append(t.Sessions, Session{Start: t.Started, Stop: now})
 		t.Started = time.Time{}
 	}
 
+	t.History = append(t.History, Transition{
+		From:   t.State,
+		To:     newState,
+		Time:   now,
+		Reason: strings.TrimSpace(reason),
+	})
 	t.State = newState
 	return nil
 }
 
+// reopen moves a completed or deleted task back to the Assigned state. This
+// is kept separate from stateTransition, since the normal state commands
+// should never leave the terminal states. The time already worked on the
+// task is kept.
+func (t *Task) reopen(reason string) error {
+	if t.State < Completed {
+		return fmt.Errorf("Cannot reopen task, it is still %v", t.State)
+	}
+
+	t.History = append(t.History, Transition{
+		From:   t.State,
+		To:     Assigned,
+		Time:   time.Now(),
+		Reason: strings.TrimSpace(reason),
+	})
+	t.State = Assigned
+	return nil
+}
+
+// otherActiveTasks returns the IDs of the other tasks that are in progress
+func (t *Task) otherActiveTasks() []string {
+	var active []string
+	for id, task := range DB {
+		if id != t.ID && task.State == InProgress {
+			active = append(active, id)
+		}
+	}
+
+	sort.Strings(active)
+	return active
+}
+
+// isWaiting returns true if the task is hidden until its wait date
+func (t *Task) isWaiting() bool {
+	return t.Wait.After(time.Now())
+}
+
 // UpdateID updates the ID for the task. Right now, this is solely based
 // on the creation time, so that it doesn't change when the user changes
 // the due date or any other field.
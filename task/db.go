@@ -1,7 +1,7 @@
 package task
 
 import (
-	"fmt"
+	"time"
 
 	"nirenjan.org/overlord/database"
 	"nirenjan.org/overlord/util"
@@ -31,12 +31,23 @@ func BuildDb() error {
 }
 
 func AddDbEntry(task Task) {
-	// We don't need the notes for the database, so clear them
+	// We don't need the notes or annotations for the database, so clear them
 	task.Notes = ""
+	task.Annotations = nil
 
 	DB[task.ID] = task
 }
 
+// uniqueID updates the ID for a newly created task, bumping the creation
+// time until the ID no longer collides with an existing task.
+func uniqueID(task *Task) {
+	task.UpdateID()
+	for _, exists := DB[task.ID]; exists; _, exists = DB[task.ID] {
+		task.Created = task.Created.Add(time.Second)
+		task.UpdateID()
+	}
+}
+
 func DeleteDbEntry(task Task) {
 	delete(DB, task.ID)
 }
@@ -49,14 +60,16 @@ func SaveDb() error {
 	return database.Save(DB)
 }
 
+// getTask reads the task referred to by id, which may be the full ID, a
+// number from the working set, or a unique prefix of the ID
 func getTask(id string) (Task, error) {
-	task, ok := DB[id]
-	if !ok {
-		return task, fmt.Errorf("Task %v not found", id)
+	var task Task
+	id, err := resolveID(id)
+	if err != nil {
+		return task, err
 	}
 
-	var err error
-	task, err = ReadFile(task.Path)
+	task, err = ReadFile(DB[id].Path)
 	if err != nil {
 		return task, err
 	}
@@ -0,0 +1,205 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"nirenjan.org/overlord/cli"
+)
+
+func registerDependHandler(root *cli.Command) error {
+	// task depend
+	cmd := cli.Cmd{
+		Command:   "depend",
+		Usage:     "<id> <other-id> [<other-id> ...]",
+		BriefHelp: "make a task depend on other tasks",
+		LongHelp: `
+Make a task depend on one or more other tasks. A task with unfinished
+dependencies is treated as blocked, and may not be started until all
+of its dependencies have been completed or deleted.
+`,
+		Handler: dependHandler,
+		Args:    cli.AtLeast,
+		Count:   2,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task undepend
+	cmd = cli.Cmd{
+		Command:   "undepend",
+		Usage:     "<id> <other-id> [<other-id> ...]",
+		BriefHelp: "remove task dependencies",
+		LongHelp: `
+Remove one or more dependencies from a task.
+`,
+		Handler: dependHandler,
+		Args:    cli.AtLeast,
+		Count:   2,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func dependHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(args[1])
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args[2:] {
+		var other Task
+		other, err = getTask(id)
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "depend" {
+			err = task.addDependency(other.ID)
+		} else {
+			err = task.removeDependency(other.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+// addDependency makes the task depend on the task with the given ID,
+// rejecting any dependency that would create a cycle.
+func (t *Task) addDependency(id string) error {
+	if id == t.ID {
+		return fmt.Errorf("Task %v cannot depend on itself", id)
+	}
+
+	for _, dep := range t.Depends {
+		if dep == id {
+			return nil
+		}
+	}
+
+	if dependsOn(id, t.ID, make(map[string]bool)) {
+		return fmt.Errorf("Task %v already depends on %v, cannot create a cycle",
+			id, t.ID)
+	}
+
+	t.Depends = append(t.Depends, id)
+	return nil
+}
+
+// removeDependency removes the dependency on the task with the given ID
+func (t *Task) removeDependency(id string) error {
+	for i, dep := range t.Depends {
+		if dep == id {
+			t.Depends = append(t.Depends[:i], t.Depends[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Task %v does not depend on %v", t.ID, id)
+}
+
+// dependsOn returns true if the task with the given ID depends, directly or
+// indirectly, on the target task
+func dependsOn(id, target string, visited map[string]bool) bool {
+	if visited[id] {
+		return false
+	}
+	visited[id] = true
+
+	for _, dep := range DB[id].Depends {
+		if dep == target || dependsOn(dep, target, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openDependencies returns the IDs of all the dependencies of the task that
+// have not yet been completed or deleted. Dependencies that are no longer in
+// the database are considered to be finished.
+func (t *Task) openDependencies() []string {
+	var open []string
+	for _, id := range t.Depends {
+		dep, ok := DB[id]
+		if ok && dep.State < Completed {
+			open = append(open, id)
+		}
+	}
+
+	return open
+}
+
+// dependents returns the IDs of all the tasks that depend on this task
+func (t *Task) dependents() []string {
+	var ids []string
+	for _, task := range sortedTaskList() {
+		for _, dep := range task.Depends {
+			if dep == t.ID {
+				ids = append(ids, task.ID)
+				break
+			}
+		}
+	}
+
+	return ids
+}
+
+// EffectiveState returns the state of the task, treating assigned tasks
+// that are waiting on unfinished dependencies as blocked.
+func (t *Task) EffectiveState() State {
+	if t.State == Assigned && len(t.openDependencies()) != 0 {
+		return Blocked
+	}
+
+	return t.State
+}
+
+// showChain displays the dependency chain of the task, following either the
+// upstream dependencies or the downstream dependents.
+func showChain(out io.Writer, ids []string, upstream bool, depth int, visited map[string]bool) {
+	indent := strings.Repeat("    ", depth)
+	for _, id := range ids {
+		task, ok := DB[id]
+		if !ok {
+			fmt.Fprintf(out, "%v%-12v(removed)\n", indent, id)
+			continue
+		}
+
+		fmt.Fprintf(out, "%v%-12v%v%v\n", indent, id, task.Status(), task.Description)
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		if upstream {
+			showChain(out, task.Depends, upstream, depth+1, visited)
+		} else {
+			showChain(out, task.dependents(), upstream, depth+1, visited)
+		}
+	}
+}
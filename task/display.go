@@ -3,6 +3,7 @@ package task
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,7 +12,7 @@ import (
 
 // Display the summary header
 func Header(out io.StringWriter) {
-	out.WriteString("ID          Due Date    Pri  Description/Status\n")
+	out.WriteString("  # ID          Due Date          Pri  Urg   Description/Status\n")
 	out.WriteString(terminal.HorizontalLine())
 	out.WriteString("\n")
 }
@@ -41,6 +42,23 @@ func (s State) Symbol() string {
 	return fmt.Sprintf("(%d)", s)
 }
 
+// hasDueTime returns true if the task is due at a specific time of day,
+// rather than by the end of the day
+func (t *Task) hasDueTime() bool {
+	h, m, s := t.Due.Clock()
+	return h != 23 || m != 59 || s != 59
+}
+
+// dueString formats the due date with the given layout, followed by the
+// time if the task is due at a specific time of day
+func (t *Task) dueString(layout string) string {
+	if t.hasDueTime() {
+		return t.Due.Format(layout + " 15:04")
+	}
+
+	return t.Due.Format(layout)
+}
+
 func (t *Task) DueSymbol() string {
 	if t.State >= InProgress && t.State <= Assigned {
 		due := time.Until(t.Due)
@@ -60,7 +78,16 @@ func (t *Task) DueSymbol() string {
 
 // Task Status as symbols
 func (t *Task) Status() string {
-	status := t.State.Symbol() + t.DueSymbol() + " "
+	status := t.EffectiveState().Symbol() + t.DueSymbol()
+	if t.isWaiting() {
+		// Sleeping
+		status += "\U0001F4A4"
+	}
+	if t.overEstimate() {
+		// Warning, over the estimate
+		status += "\u26A0\uFE0F "
+	}
+	status += " "
 
 	return status
 }
@@ -69,18 +96,21 @@ func (t *Task) Status() string {
 func (t *Task) Summary(out io.Writer) {
 	s := fmt.Sprintf("%-12v", t.ID)
 	if t.State <= Blocked {
-		s += fmt.Sprintf("%-12v ", t.Due.Format("2006-01-02"))
+		s += fmt.Sprintf("%-17v ", t.dueString("2006-01-02"))
 	} else {
-		s += strings.Repeat(" ", 13)
+		s += strings.Repeat(" ", 18)
 	}
 
 	if t.State <= Deferred {
-		s += fmt.Sprintf("%v   ", t.Priority)
+		s += fmt.Sprintf("%v   %4.1f  ", t.Priority, t.Urgency())
 	} else {
-		s += strings.Repeat(" ", 4)
+		s += strings.Repeat(" ", 10)
 	}
 
 	s += fmt.Sprint(t.Status(), t.Description)
+	if done, total := t.subtaskProgress(); total != 0 {
+		s += fmt.Sprintf(" [%v/%v]", done, total)
+	}
 	fmt.Fprintln(out, s)
 }
 
@@ -93,9 +123,11 @@ func (t *Task) Show(out io.Writer) {
 	// then the due date doesn't make any sense
 	if t.State <= Blocked {
 		due := time.Until(t.Due)
-		fmt.Fprintf(out, "Due:      %v ", t.Due.Format("Mon, Jan 2 2006"))
+		fmt.Fprintf(out, "Due:      %v ", t.dueString("Mon, Jan 2 2006"))
 		if due <= 0 {
 			fmt.Fprintln(out, terminal.Foreground(terminal.Red)+"OVERDUE"+terminal.Reset())
+		} else if t.hasDueTime() && due < 24*time.Hour {
+			fmt.Fprintf(out, "(in %dh %02dm)\n", int(due.Hours()), int(due.Minutes())%60)
 		} else {
 			due = due.Round(24 * time.Hour)
 			fmt.Fprintf(out, "(in %v days)\n", int(due/(24*time.Hour)))
@@ -106,15 +138,68 @@ func (t *Task) Show(out io.Writer) {
 	// or deleted
 	if t.State < Completed {
 		fmt.Fprintln(out, "Priority:", t.Priority)
+		fmt.Fprintf(out, "Urgency:  %.1f\n", t.Urgency())
+	}
+	fmt.Fprintln(out, "Status:  ", t.EffectiveState())
+	if t.isWaiting() {
+		fmt.Fprintln(out, "Waiting:  until", t.Wait.Format("Mon, Jan 2 2006 15:04"))
+	}
+	if t.Project != "" {
+		fmt.Fprintln(out, "Project: ", t.Project)
+	}
+	if len(t.Tags) != 0 {
+		fmt.Fprintln(out, "Tags:    ", strings.Join(t.Tags, " "))
+	}
+	if t.Repeat != "" {
+		fmt.Fprintln(out, "Repeat:  ", t.Repeat)
 	}
-	fmt.Fprintln(out, "Status:  ", t.State)
 
-	worked := t.Worked
-	if t.State == InProgress {
-		worked += time.Since(t.Started)
+	if t.Estimate != 0 {
+		fmt.Fprintln(out, "Estimate:", t.estimateString())
 	}
+
+	worked := t.totalWorked()
 	if worked != 0 {
-		fmt.Fprintln(out, "Worked:  ", worked.Round(time.Second))
+		if n := len(t.Sessions); n > 1 {
+			fmt.Fprintf(out, "Worked:   %v in %v sessions\n", worked.Round(time.Second), n)
+		} else {
+			fmt.Fprintln(out, "Worked:  ", worked.Round(time.Second))
+		}
+	}
+
+	if t.Focus != 0 {
+		fmt.Fprintln(out, "Focus:   ", t.Focus, "blocks completed")
+	}
+
+	if done, total := t.subtaskProgress(); total != 0 {
+		fmt.Fprintf(out, "Checklist: %v/%v\n", done, total)
+		for i, sub := range t.Subtasks {
+			mark := " "
+			if sub.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(out, "    %2d. [%v] %v\n", i+1, mark, sub.Text)
+		}
+	}
+
+	if len(t.Depends) != 0 {
+		fmt.Fprintln(out, "Depends on:")
+		showChain(out, t.Depends, true, 1, make(map[string]bool))
+	}
+	if dependents := t.dependents(); len(dependents) != 0 {
+		fmt.Fprintln(out, "Required by:")
+		showChain(out, dependents, false, 1, make(map[string]bool))
+	}
+
+	if len(t.Annotations) != 0 {
+		fmt.Fprintln(out, "Annotations:")
+		annotations := append([]Annotation(nil), t.Annotations...)
+		sort.SliceStable(annotations, func(i, j int) bool {
+			return annotations[i].Time.Before(annotations[j].Time)
+		})
+		for _, a := range annotations {
+			fmt.Fprintf(out, "    %v  %v\n", a.Time.Format("Mon, Jan 2 2006 15:04"), a.Text)
+		}
 	}
 
 	if len(t.Notes) != 0 {
@@ -1,23 +1,30 @@
 package task
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"strings"
 	"time"
 
 	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/util"
 )
 
 func registerEditHandler(root *cli.Command) error {
 	// task due
 	cmd := cli.Cmd{
 		Command:   "due",
-		Usage:     "<id> <date-YYYY-MM-DD>",
+		Usage:     "[-filter expr] [-y] [id ...] <date>",
 		BriefHelp: "change task due date",
-		LongHelp:  "\nChange the due date for a task.\n",
-		Handler:   editHandler,
-		Args:      cli.Exact,
-		Count:     2,
+		LongHelp: `
+Change the due date for a task. The date may be given as a date such as
+2026-11-05, a date and time such as 2026-11-05T14:00, or a relative date
+such as today, tomorrow, friday, next monday, +3d, +2w or eom.
+` + bulkHelp,
+		Handler: bulkEditHandler,
+		Args:    cli.AtLeast,
+		Count:   1,
 	}
 
 	_, err := cli.RegisterCommand(root, cmd)
@@ -28,12 +35,12 @@ func registerEditHandler(root *cli.Command) error {
 	// task priority
 	cmd = cli.Cmd{
 		Command:   "priority",
-		Usage:     "<id> {0-9}",
+		Usage:     "[-filter expr] [-y] [id ...] {0-9}",
 		BriefHelp: "change task priority",
-		LongHelp:  "\nChange the priority for a task.\n",
-		Handler:   editHandler,
-		Args:      cli.Exact,
-		Count:     2,
+		LongHelp:  "\nChange the priority for a task.\n" + bulkHelp,
+		Handler:   bulkEditHandler,
+		Args:      cli.AtLeast,
+		Count:     1,
 	}
 
 	_, err = cli.RegisterCommand(root, cmd)
@@ -57,6 +64,114 @@ func registerEditHandler(root *cli.Command) error {
 		return err
 	}
 
+	// task repeat
+	cmd = cli.Cmd{
+		Command:   "repeat",
+		Usage:     "<id> <rule>",
+		BriefHelp: "change task repeat rule",
+		LongHelp: `
+Change the repeat rule for a task. When a repeating task is completed,
+the next instance is created automatically, with the due date advanced
+according to the rule. Deleting a repeating task ends the series.
+
+The following rules are supported
+
+	daily                   Every day
+	weekly                  Every week, on the same weekday as the due date
+	weekly:mon,fri          Every week, on the given weekdays
+	monthly                 Every month, on the same day as the due date
+	monthly:15              Every month, on the given day
+	after:3                 3 days after the task was completed
+	none                    Stop repeating the task
+`,
+		Handler: editHandler,
+		Args:    cli.Exact,
+		Count:   2,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task wait
+	cmd = cli.Cmd{
+		Command:   "wait",
+		Usage:     "<id> <date|none>",
+		BriefHelp: "hide task until a date",
+		LongHelp: `
+Hide a task from the pending task list until the given date, after which
+it reappears automatically. Unlike deferring a task, the task keeps its
+state and due date. Use "none" to show the task again right away.
+Waiting tasks may be listed with "task list waiting".
+`,
+		Handler: editHandler,
+		Args:    cli.AtLeast,
+		Count:   2,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task estimate
+	cmd = cli.Cmd{
+		Command:   "estimate",
+		Usage:     "<id> <estimate>",
+		BriefHelp: "change task time estimate",
+		LongHelp: `
+Change the estimate of the time needed to complete a task, for example
+3h or 1h30m. Use "none" to remove the estimate.
+`,
+		Handler: editHandler,
+		Args:    cli.Exact,
+		Count:   2,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task project
+	cmd = cli.Cmd{
+		Command:   "project",
+		Usage:     "<id> [project]",
+		BriefHelp: "change task project",
+		LongHelp: `
+Change the project that a task belongs to. If the project is not given,
+then the task is removed from its project.
+`,
+		Handler: editHandler,
+		Args:    cli.AtLeast,
+		Count:   1,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task tag
+	cmd = cli.Cmd{
+		Command:   "tag",
+		Usage:     "<id> [tag ...]",
+		BriefHelp: "retag the task with the given tags",
+		LongHelp: `
+Replace the tags on a task with the given tags. Tags may be separated by
+spaces or commas. If no tags are given, then all tags are removed.
+`,
+		Handler: editHandler,
+		Args:    cli.AtLeast,
+		Count:   1,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
 	// task notes
 	cmd = cli.Cmd{
 		Command:   "notes",
@@ -90,33 +205,68 @@ func editHandler(cmd *cli.Command, args []string) error {
 
 	// Perform argument validation and specific handling
 	switch args[0] {
-	case "due":
+	case "desc":
+		{
+			desc := strings.TrimSpace(strings.Join(args[2:], " "))
+			if len(desc) == 0 {
+				return fmt.Errorf("Invalid task description")
+			}
+
+			task.Description = desc
+		}
+
+	case "repeat":
 		{
-			dueDate, err := time.ParseInLocation("2006-01-02", args[2], time.Local)
+			repeat, err := parseRecurrence(args[2])
 			if err != nil {
 				return err
 			}
+			task.Repeat = repeat.anchor(task.Due)
+		}
+
+	case "wait":
+		{
+			date := strings.Join(args[2:], " ")
+			if date == "none" {
+				task.Wait = time.Time{}
+				break
+			}
 
-			task.Due = dueDate.Add(86399 * time.Second)
+			wait, _, err := util.ParseDate(date, time.Now())
+			if err != nil {
+				return err
+			}
+			task.Wait = wait
 		}
 
-	case "priority":
+	case "estimate":
 		{
-			pri, err := parsePriority(args[2])
+			estimate, err := parseEstimate(args[2])
 			if err != nil {
 				return err
 			}
-			task.Priority = pri
+			task.Estimate = estimate
 		}
 
-	case "desc":
+	case "project":
 		{
-			desc := strings.TrimSpace(strings.Join(args[2:], " "))
-			if len(desc) == 0 {
-				return fmt.Errorf("Invalid task description")
+			if len(args) > 3 {
+				cmd.Usage()
 			}
 
-			task.Description = desc
+			task.Project = ""
+			if len(args) == 3 {
+				task.Project = args[2]
+			}
+		}
+
+	case "tag":
+		{
+			tags, err := parseTags(args[2:])
+			if err != nil {
+				return err
+			}
+			task.Tags = tags
 		}
 
 	case "notes":
@@ -140,3 +290,65 @@ func editHandler(cmd *cli.Command, args []string) error {
 	AddDbEntry(task)
 	return SaveDb()
 }
+
+// bulkEditHandler changes the due date or priority of one or more tasks
+func bulkEditHandler(cmd *cli.Command, args []string) error {
+	var bulk bulkOptions
+
+	fs := flag.NewFlagSet("overlord task "+args[0], flag.ContinueOnError)
+	bulk.addFlags(fs)
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	pos, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	err = LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	ids, pos, err = bulk.selectTasks(pos, 1)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		cmd.Usage()
+	}
+
+	var update func(task *Task)
+	var field string
+	switch args[0] {
+	case "due":
+		field = "due date"
+		dueDate, err := parseDue(strings.Join(pos, " "))
+		if err != nil {
+			return err
+		}
+		update = func(task *Task) {
+			task.Due = dueDate
+		}
+
+	case "priority":
+		field = "priority"
+		if len(pos) != 1 {
+			cmd.Usage()
+		}
+		pri, err := parsePriority(pos[0])
+		if err != nil {
+			return err
+		}
+		update = func(task *Task) {
+			task.Priority = pri
+		}
+	}
+
+	return bulk.apply("Change the "+field+" of", ids, func(task *Task) error {
+		update(task)
+		return saveTask(task)
+	})
+}
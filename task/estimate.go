@@ -0,0 +1,160 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+// parseEstimate parses a time estimate such as 3h or 1h30m. An estimate of
+// "none" or 0 clears the estimate.
+func parseEstimate(s string) (time.Duration, error) {
+	if s == "none" {
+		return 0, nil
+	}
+
+	estimate, err := time.ParseDuration(s)
+	if err != nil || estimate < 0 {
+		return 0, fmt.Errorf("Invalid estimate '%v'", s)
+	}
+
+	return estimate, nil
+}
+
+// totalWorked returns the time worked on the task, including the time in
+// the current session if the task is in progress
+func (t *Task) totalWorked() time.Duration {
+	worked := t.Worked
+	if t.State == InProgress {
+		worked += time.Since(t.Started)
+	}
+
+	return worked
+}
+
+// overEstimate returns true if more time has been spent on the task than
+// was estimated
+func (t *Task) overEstimate() bool {
+	return t.Estimate != 0 && t.totalWorked() > t.Estimate
+}
+
+// estimateString describes the time remaining on the estimate
+func (t *Task) estimateString() string {
+	remaining := t.Estimate - t.totalWorked()
+	if remaining < 0 {
+		return fmt.Sprintf("%v (%v over)", hoursString(t.Estimate),
+			hoursString(-remaining))
+	}
+
+	return fmt.Sprintf("%v (%v remaining)", hoursString(t.Estimate),
+		hoursString(remaining))
+}
+
+func registerEstimateReport(root *cli.Command) error {
+	// task report estimate
+	cmd := cli.Cmd{
+		Command:   "estimate",
+		Usage:     "[project]",
+		BriefHelp: "compare estimates to the time worked",
+		LongHelp: `
+Compare the estimated time to the time actually worked, for the completed
+tasks that have an estimate, including archived tasks, totalled per
+project. The actual column is the time worked as a percentage of the
+estimate, so values above 100% mean that the tasks took longer than
+planned. If a project is given, then only that project is included.
+`,
+		Handler: estimateReportHandler,
+		Args:    cli.AtMost,
+		Count:   1,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+// estimateEntry is the total estimated and worked time for a project
+type estimateEntry struct {
+	project  string
+	tasks    int
+	estimate time.Duration
+	worked   time.Duration
+}
+
+// accuracy returns the ratio of the time worked to the estimate as a
+// percentage
+func (e estimateEntry) accuracy() float64 {
+	return 100 * float64(e.worked) / float64(e.estimate)
+}
+
+func (e estimateEntry) show(out io.Writer) {
+	fmt.Fprintf(out, "%-40v%6v%11v%11v%9.0f%%\n", e.project, e.tasks,
+		hoursString(e.estimate), hoursString(e.worked), e.accuracy())
+}
+
+func estimateReportHandler(cmd *cli.Command, args []string) error {
+	tasks, err := reportTasks()
+	if err != nil {
+		return err
+	}
+
+	var project string
+	if len(args) == 2 {
+		project = args[1]
+	}
+
+	projects := make(map[string]*estimateEntry)
+	var total = estimateEntry{project: "Total"}
+	for _, task := range tasks {
+		if task.State != Completed || task.Estimate == 0 {
+			continue
+		}
+		if !task.InProject(project) {
+			continue
+		}
+
+		name := task.Project
+		if name == "" {
+			name = "(none)"
+		}
+
+		e, ok := projects[name]
+		if !ok {
+			e = &estimateEntry{project: name}
+			projects[name] = e
+		}
+
+		for _, entry := range []*estimateEntry{e, &total} {
+			entry.tasks++
+			entry.estimate += task.Estimate
+			entry.worked += task.Worked
+		}
+	}
+
+	if total.tasks == 0 {
+		return fmt.Errorf("No completed tasks with estimates")
+	}
+
+	var names []string
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := util.NewPager()
+	fmt.Fprintf(out, "%-40v%6v%11v%11v%10v\n", "Project", "Tasks", "Estimated",
+		"Worked", "Actual")
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	for _, name := range names {
+		projects[name].show(out)
+	}
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	total.show(out)
+	out.Show()
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/config"
+)
+
+func registerFilterHandler(root *cli.Command) error {
+	var cmd cli.Cmd
+	var err error
+	var filterRoot *cli.Command
+	cmd = cli.Cmd{
+		Command:   "filter",
+		Usage:     "...",
+		BriefHelp: "manage saved task filters",
+		LongHelp: `
+Manage saved task filters. A saved filter may be used anywhere a filter
+expression is accepted, by giving its name, for example "task list mine"
+or "task list mine pri<=3". See "task list -h" for the filter syntax.
+`,
+	}
+
+	filterRoot, err = cli.RegisterCommandGroup(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task filter save
+	cmd = cli.Cmd{
+		Command:   "save",
+		Usage:     "<name> <filter>",
+		BriefHelp: "save a named filter",
+		LongHelp:  "\nSave the filter expression under the given name.\n",
+		Handler:   filterHandler,
+		Args:      cli.AtLeast,
+		Count:     2,
+	}
+
+	_, err = cli.RegisterCommand(filterRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task filter list
+	cmd = cli.Cmd{
+		Command:   "list",
+		Usage:     " ",
+		BriefHelp: "list built-in and saved filters",
+		LongHelp:  "\nList the built-in and saved filters.\n",
+		Handler:   filterHandler,
+		Args:      cli.None,
+	}
+
+	_, err = cli.RegisterCommand(filterRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task filter rm
+	cmd = cli.Cmd{
+		Command:   "rm",
+		Usage:     "<name>",
+		BriefHelp: "remove a saved filter",
+		LongHelp:  "\nRemove the saved filter with the given name.\n",
+		Handler:   filterHandler,
+		Args:      cli.Exact,
+		Count:     1,
+	}
+
+	_, err = cli.RegisterCommand(filterRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func filterHandler(cmd *cli.Command, args []string) error {
+	switch args[0] {
+	case "save":
+		name := args[1]
+		err := validFilterName(name)
+		if err != nil {
+			return err
+		}
+
+		// Make sure the filter is valid before saving it
+		expr := strings.Join(args[2:], " ")
+		_, err = parseQuery(expr)
+		if err != nil {
+			return err
+		}
+
+		return config.Set(savedFilterKey(name), expr)
+
+	case "list":
+		var names []string
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("%-16v%v\n", name, presets[name])
+		}
+		for _, name := range savedFilters() {
+			expr, _ := namedFilter(name)
+			fmt.Printf("%-16v%v\n", name, expr)
+		}
+
+	case "rm":
+		if _, ok := config.Get(savedFilterKey(args[1])); !ok {
+			return fmt.Errorf("Filter %v not found", args[1])
+		}
+
+		return config.Unset(savedFilterKey(args[1]))
+	}
+
+	return nil
+}
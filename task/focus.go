@@ -0,0 +1,229 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+)
+
+func registerFocusHandler(root *cli.Command) error {
+	// task focus
+	cmd := cli.Cmd{
+		Command:   "focus",
+		Usage:     "<id> [-minutes N] [-break N] [-rounds N]",
+		BriefHelp: "work on a task with a focus timer",
+		LongHelp: `
+Start working on a task for a fixed block of time, showing a countdown
+until the end of the block. The task is stopped at the end of the block,
+or if the timer is interrupted with Ctrl-C, and the time is recorded as
+a work session. If the task is already in progress, the current session
+is stopped first, so that each block is recorded as its own session. Only
+blocks that run to the end count towards the focus blocks completed on
+the task. This command accepts the following options
+
+	-minutes N              Length of each focus block (default 25)
+	-break N                Length of the break between blocks (default 5)
+	-rounds N               Number of focus blocks (default 1)
+`,
+		Handler: focusHandler,
+		Args:    cli.AtLeast,
+		Count:   1,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+// updateTask reloads the database and the task, and applies the update to
+// the task before saving it. This is used by long running commands, so that
+// changes made by other commands in the meantime are not lost.
+func updateTask(id string, update func(task *Task) error) error {
+	DB = make(map[string]Task)
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(id)
+	if err != nil {
+		return err
+	}
+
+	err = update(&task)
+	if err != nil {
+		return err
+	}
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+// countdown displays a countdown until the end time. It returns false if
+// the countdown was interrupted.
+func countdown(label string, end time.Time, interrupt <-chan os.Signal) bool {
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		remaining := end.Sub(now).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		// The line is sized to fit the current width of the terminal, with
+		// the remaining space used for a progress bar
+		clock := fmt.Sprintf(" %02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+		line := label
+		width := terminal.Width() - 1
+		if len(line)+len(clock) > width {
+			if width > len(clock)+3 {
+				line = line[:width-len(clock)-3] + "..."
+			} else {
+				line = ""
+			}
+		}
+		if bar := width - len(line) - len(clock) - 3; bar > 0 {
+			done := int(float64(bar) * float64(now.Sub(start)) / float64(end.Sub(start)))
+			if done > bar {
+				done = bar
+			}
+			line += " [" + strings.Repeat("#", done) + strings.Repeat("-", bar-done) + "]"
+		}
+		fmt.Print(terminal.ClearLine() + line + clock)
+
+		if remaining == 0 {
+			fmt.Println("\a")
+			return true
+		}
+
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			fmt.Println()
+			return false
+		}
+	}
+}
+
+func focusHandler(cmd *cli.Command, args []string) error {
+	var minutes, pause, rounds int
+
+	fs := flag.NewFlagSet("overlord task focus", flag.ContinueOnError)
+	fs.IntVar(&minutes, "minutes", 25, "focus minutes")
+	fs.IntVar(&pause, "break", 5, "break minutes")
+	fs.IntVar(&rounds, "rounds", 1, "focus blocks")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	pos, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		cmd.Usage()
+	}
+
+	if minutes <= 0 || pause < 0 || rounds <= 0 {
+		return fmt.Errorf("Invalid focus timer options")
+	}
+
+	err = LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(pos[0])
+	if err != nil {
+		return err
+	}
+	id := task.ID
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	for round := 1; round <= rounds; round++ {
+		// A task that is already in progress is stopped first, so that
+		// the work before the block is kept in a separate session
+		var started time.Time
+		err = updateTask(id, func(t *Task) error {
+			if t.State == InProgress {
+				err := t.stateTransition(Assigned, "stopped for focus timer")
+				if err != nil {
+					return err
+				}
+			}
+
+			err := t.stateTransition(InProgress, "focus timer started")
+			task = *t
+			started = t.Started
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		label := fmt.Sprintf("Focus %v/%v: %v", round, rounds, task.Description)
+		end := time.Now().Add(time.Duration(minutes) * time.Minute)
+		completed := countdown(label, end, interrupt)
+
+		err = updateTask(id, func(t *Task) error {
+			// Leave the task alone if it was stopped or restarted by
+			// another command during the block. The task file only
+			// keeps the start time to the second.
+			if t.State != InProgress || t.Started.Unix() != started.Unix() {
+				return nil
+			}
+
+			reason := "focus timer interrupted"
+			if completed {
+				reason = "focus block completed"
+			}
+
+			err := t.stateTransition(Assigned, reason)
+			if err != nil {
+				return err
+			}
+			t.Sessions[len(t.Sessions)-1].Comment = "focus"
+			if completed {
+				t.Focus++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if !completed {
+			fmt.Println("Focus interrupted, time recorded")
+			return nil
+		}
+
+		if round < rounds && pause > 0 {
+			end = time.Now().Add(time.Duration(pause) * time.Minute)
+			if !countdown("Break", end, interrupt) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
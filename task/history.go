@@ -0,0 +1,132 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/terminal"
+)
+
+// Transition is a single change in the state of a task
+type Transition struct {
+	From   State     `json:"from"`
+	To     State     `json:"to"`
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// parseTransition parses a state transition from the task file, in the form
+// "<time> <from> <to> [reason]"
+func parseTransition(value string) (Transition, error) {
+	var tr Transition
+	var err error
+
+	fields := strings.SplitN(value, " ", 4)
+	if len(fields) < 3 {
+		return tr, fmt.Errorf("Invalid state transition '%v'", value)
+	}
+
+	tr.Time, err = time.ParseInLocation(time.RFC3339, fields[0], time.Local)
+	if err != nil {
+		return tr, err
+	}
+
+	tr.From, err = parseState(fields[1])
+	if err != nil {
+		return tr, err
+	}
+
+	tr.To, err = parseState(fields[2])
+	if err != nil {
+		return tr, err
+	}
+
+	if len(fields) == 4 {
+		tr.Reason = fields[3]
+	}
+
+	return tr, nil
+}
+
+// formatTransition formats a state transition for the task file
+func formatTransition(tr Transition) string {
+	return strings.TrimSpace(fmt.Sprintf("%v %v %v %v", tr.Time.Format(time.RFC3339),
+		tr.From, tr.To, tr.Reason))
+}
+
+// stateDurations returns the time that the task spent in each state
+// between the start and end times. Tasks that were created before the
+// history was recorded are treated as being in their first recorded state
+// from the time they were created.
+func (t *Task) stateDurations(start, end time.Time) map[State]time.Duration {
+	durations := make(map[State]time.Duration)
+	add := func(state State, from, to time.Time) {
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			durations[state] += to.Sub(from)
+		}
+	}
+
+	since := t.Created
+	for _, tr := range t.History {
+		add(tr.From, since, tr.Time)
+		since = tr.Time
+	}
+
+	if t.State < Completed {
+		add(t.State, since, end)
+	}
+
+	return durations
+}
+
+// completedAt returns the time that the task was completed, or the zero
+// time if the task is not complete, or was completed before the history
+// was recorded
+func (t *Task) completedAt() time.Time {
+	if t.State != Completed {
+		return time.Time{}
+	}
+
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].To == Completed {
+			return t.History[i].Time
+		}
+	}
+
+	return time.Time{}
+}
+
+// ShowHistory displays the state transitions of the task, along with the
+// time spent in each state
+func (t *Task) ShowHistory(out io.Writer) {
+	fmt.Fprintln(out, "History:")
+	fmt.Fprintf(out, "    %v  created\n", t.Created.Format("Mon, Jan 2 2006 15:04"))
+
+	for i, tr := range t.History {
+		var until time.Time
+		if i+1 < len(t.History) {
+			until = t.History[i+1].Time
+		} else if tr.To < Completed {
+			until = time.Now()
+		}
+
+		duration := ""
+		if !until.IsZero() {
+			duration = fmt.Sprintf("(%v)", hoursString(until.Sub(tr.Time)))
+		}
+
+		line := fmt.Sprintf("    %v  %-26v %-9v %v", tr.Time.Format("Mon, Jan 2 2006 15:04"),
+			fmt.Sprintf("%v -> %v", tr.From, tr.To), duration, tr.Reason)
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+
+	fmt.Fprintln(out, terminal.HorizontalLine())
+}
@@ -1,8 +1,11 @@
 package task
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"sort"
-	"time"
+	"strings"
 
 	"nirenjan.org/overlord/cli"
 	"nirenjan.org/overlord/log"
@@ -41,27 +44,54 @@ func (tl TaskList) Less(i, j int) bool {
 	}
 
 	// Priorities are still equal, sort by state
-	if t1.State != t2.State {
-		return t1.State < t2.State
+	s1, s2 := t1.EffectiveState(), t2.EffectiveState()
+	if s1 != s2 {
+		return s1 < s2
 	}
 
 	// All else being equal, sort by creation date
 	return t1.Created.Sub(t2.Created) < 0
 }
 
+const listUsage = "[-project name] [-tag tag,...] [-sort keys] [filter]"
+
 func registerListHandler(root *cli.Command) error {
 	// task list
 	cmd := cli.Cmd{
 		Command:   "list",
-		Usage:     "[type]",
+		Usage:     "[type] [-project name] [-tag tag,...] [-sort keys] [filter]",
 		BriefHelp: "list all pending tasks",
 		LongHelp: `
 List all pending tasks. This includes tasks that are overdue, due shortly,
 in progress (but not due shortly), and tasks that haven't been started.
+
+If a filter expression is given, list the tasks matching the filter
+instead. Filters are made up of terms such as "state:in-progress,blocked",
+"pri<=3", "due<2026-11-01", "desc~deploy", "project:web" and "tag:ops",
+combined with "and", "or", "not" and parentheses. Dates may also be
+relative, such as "due<=tomorrow" or "created>-2w", and "closed" is the
+date that a task was completed or deleted. A term may also be the name
+of a task type or a saved filter, see "task filter -h".
+
+Each of the task types accepts the following options and a filter
+expression to further filter the list
+
+	-project name           Only list tasks in the given project
+	-tag tag,...            Only list tasks with any of the given tags
+	-sort keys              Comma separated list of keys to sort the list
+	                        by, see below
+
+The sort keys are due, pri, urgency, state, created, desc, project and
+id. Prefix a key with - to sort in descending order, for example
+"pri,-due". The most urgent tasks sort first, the same as for priority.
+The default sort order may be changed with the "task.sort" setting.
+
+Any task command may refer to a task by its number in the last list,
+rather than by its ID. Task IDs may also be shortened to any prefix
+that is unique.
 `,
 		Handler:    listHandler,
-		Args:       cli.AtMost,
-		Count:      1,
+		Args:       cli.Any,
 		Subcommand: "Task Types",
 	}
 
@@ -73,8 +103,7 @@ in progress (but not due shortly), and tasks that haven't been started.
 	// task list pending
 	// This is the same as task list, so just change the relevant fields
 	cmd.Command = "pending"
-	cmd.Usage = " "
-	cmd.Args = cli.None
+	cmd.Usage = listUsage
 
 	_, err = cli.RegisterCommand(taskList, cmd)
 	if err != nil {
@@ -84,12 +113,13 @@ in progress (but not due shortly), and tasks that haven't been started.
 	// task list overdue
 	cmd = cli.Cmd{
 		Command:   "overdue",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all overdue tasks",
 		LongHelp: `
 List all tasks that are past their due date, ordered by priority.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -100,13 +130,14 @@ List all tasks that are past their due date, ordered by priority.
 	// task list due
 	cmd = cli.Cmd{
 		Command:   "due",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all tasks due shortly",
 		LongHelp: `
 List all tasks that are due within the next week, ordered by the due
 date, then by their priority.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -117,13 +148,14 @@ date, then by their priority.
 	// task list in-progress
 	cmd = cli.Cmd{
 		Command:   "in-progress",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all tasks that are in progress",
 		LongHelp: `
 List all tasks that are currently in progress, ordered by the due date
 and then by the priority.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -134,12 +166,13 @@ and then by the priority.
 	// task list completed
 	cmd = cli.Cmd{
 		Command:   "completed",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all completed tasks",
 		LongHelp: `
 List all tasks that are completed.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -150,12 +183,13 @@ List all tasks that are completed.
 	// task list deleted
 	cmd = cli.Cmd{
 		Command:   "deleted",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all deleted tasks",
 		LongHelp: `
 List all tasks that are deleted.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -166,12 +200,30 @@ List all tasks that are deleted.
 	// task list blocked
 	cmd = cli.Cmd{
 		Command:   "blocked",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all blocked tasks",
 		LongHelp: `
 List all tasks that are blocked.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
+	}
+
+	_, err = cli.RegisterCommand(taskList, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task list waiting
+	cmd = cli.Cmd{
+		Command:   "waiting",
+		Usage:     listUsage,
+		BriefHelp: "list all waiting tasks",
+		LongHelp: `
+List all tasks that are hidden until their wait date, see "task wait".
+`,
+		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -182,12 +234,13 @@ List all tasks that are blocked.
 	// task list deferred
 	cmd = cli.Cmd{
 		Command:   "deferred",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all deferred tasks",
 		LongHelp: `
 List all tasks that are deferred.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -198,12 +251,32 @@ List all tasks that are deferred.
 	// task list all
 	cmd = cli.Cmd{
 		Command:   "all",
-		Usage:     " ",
+		Usage:     listUsage,
 		BriefHelp: "list all tasks",
 		LongHelp: `
 List all tasks, including deferred, completed and deleted ones.
 `,
 		Handler: listHandler,
+		Args:    cli.Any,
+	}
+
+	_, err = cli.RegisterCommand(taskList, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task list archived
+	cmd = cli.Cmd{
+		Command:   "archived",
+		Usage:     listUsage,
+		BriefHelp: "list all archived tasks",
+		LongHelp: `
+List all tasks that have been moved to the archive by "task cleanup".
+Archived tasks may be restored with "task reopen", using either their
+ID or their number in the list.
+`,
+		Handler: listHandler,
+		Args:    cli.Any,
 	}
 
 	_, err = cli.RegisterCommand(taskList, cmd)
@@ -235,51 +308,90 @@ func listHandler(cmd *cli.Command, args []string) error {
 	log.Debug(cmd)
 	log.Debug(args)
 
+	var project string
+	var tags tagList
+	var order string
+
+	fs := flag.NewFlagSet("overlord task list", flag.ContinueOnError)
+	fs.StringVar(&order, "sort", "", "sort order")
+	fs.StringVar(&project, "project", "", "project name")
+	fs.Var(&tags, "tag", "comma separated tags")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	var terms []string
+	terms, err = cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	// The task types are preset filters. The top level list command
+	// defaults to pending tasks, unless it is given a filter instead.
+	expr := args[0]
+	filter := strings.Join(terms, " ")
 	tasks := sortedTaskList()
+	if expr == "archived" {
+		tasks, err = readArchive()
+		if err != nil {
+			return err
+		}
+
+		expr = "all"
+		if filter != "" {
+			expr = filter
+		}
+	} else if expr == "list" {
+		expr = "pending"
+		if filter != "" {
+			expr = filter
+		}
+	} else if filter != "" {
+		expr += " (" + filter + ")"
+	}
+
+	var q query
+	q, err = parseQuery(expr)
+	if err != nil {
+		return err
+	}
+
+	err = tasks.sortBy(order)
+	if err != nil {
+		return err
+	}
+
+	return showTaskList(tasks, func(t *Task) bool {
+		return q(t) && t.InProject(project) && t.HasTags(tags)
+	})
+}
 
+// showTaskList shows the matching tasks in a numbered list. The listed tasks
+// become the working set, and may be referred to by their number in the list.
+func showTaskList(tasks TaskList, match query) error {
+	var ids []string
 	out := util.NewPager()
 	Header(out)
 	for _, task := range tasks {
-		if task.Filter(args[0]) {
+		if match(&task) {
+			ids = append(ids, task.ID)
+			fmt.Fprintf(out, "%3d ", len(ids))
 			task.Summary(out)
 		}
 	}
 	out.Show()
 
-	return nil
+	return saveWorkingSet(ids)
 }
 
-func (t *Task) Filter(filter string) bool {
-	switch filter {
-	case "pending", "list":
-		return t.State < Deferred
-
-	case "in-progress":
-		return t.State == InProgress
-
-	case "overdue":
-		return t.State < Deferred && time.Until(t.Due) <= 0
-
-	case "due":
-		if t.State < Deferred {
-			due := time.Until(t.Due)
-			return due > 0 && due < 96*time.Hour
-		} else {
-			return false
-		}
-
-	case "blocked":
-		return t.State == Blocked
-
-	case "completed":
-		return t.State == Completed
-
-	case "deferred":
-		return t.State == Deferred
-
-	case "deleted":
-		return t.State == Deleted
-	}
+// InProject returns true if the task belongs to the given project. An empty
+// project matches all tasks.
+func (t *Task) InProject(project string) bool {
+	return project == "" || t.Project == project
+}
 
-	return true
+// HasTags returns true if the task has any of the given tags. An empty list
+// of tags matches all tasks.
+func (t *Task) HasTags(tags []string) bool {
+	return len(tags) == 0 || util.TagsIntersection(tags, t.Tags)
 }
@@ -17,15 +17,30 @@ func registerNewHandler(root *cli.Command) error {
 	// task new
 	cmd := cli.Cmd{
 		Command:   "new",
-		Usage:     "[-due YYYY-MM-DD] [-priority {0-9}] [-notes] description",
+		Usage:     "[-template name] [-due date] [-priority {0-9}] [-project name] [-tag tag,...] [-repeat rule] [-estimate time] [-notes] description",
 		BriefHelp: "add new task entry",
 		LongHelp: `
 Add a new task entry with the given parameters. This command accepts
 the following options
 
-	-due YYYY-MM-DD         The due date for the task (defaults to 1 week out)
+	-due date               The due date for the task (defaults to 1 week out).
+	                        This may be a date such as 2026-11-05, a date and
+	                        time such as 2026-11-05T14:00, or a relative date
+	                        such as today, tomorrow, friday, next monday, +3d,
+	                        +2w or eom
 	-priority {0-9}         The priority for the task (defaults to 5)
+	-project name           The project that the task belongs to
+	-tag tag,...            Comma separated list of tags for the task. This
+	                        may be given more than once
+	-repeat rule            Repeat the task when it is completed, see
+	                        "task repeat -h" for the supported rules
+	-estimate time          The estimated time to complete the task, for
+	                        example 3h or 1h30m
 	-notes                  This will open an editor to add task notes
+	-template name          Create the task from the template, see
+	                        "task template -h". The description replaces
+	                        any {} in the template, and the other options
+	                        override the template
 `,
 		Handler: newHandler,
 		Args:    cli.AtLeast,
@@ -39,10 +54,7 @@ the following options
 type dueDate time.Time
 
 func (d *dueDate) Set(s string) error {
-	var err error
-	var date time.Time
-	date, err = time.ParseInLocation("2006-01-02", s, time.Local)
-
+	date, err := parseDue(s)
 	if err == nil {
 		*d = dueDate(date)
 	}
@@ -53,6 +65,18 @@ func (d dueDate) String() string {
 	return (time.Time(d)).Format("2006-01-02")
 }
 
+type tagList []string
+
+func (l *tagList) Set(s string) error {
+	tags, err := parseTags([]string{s})
+	*l = append(*l, tags...)
+	return err
+}
+
+func (l tagList) String() string {
+	return strings.Join(l, ",")
+}
+
 type Priority int
 
 func (p *Priority) Set(s string) error {
@@ -69,9 +93,7 @@ func (p Priority) String() string {
 }
 
 func newHandler(cmd *cli.Command, args []string) error {
-	defaultDueDate, _ := time.ParseInLocation("2006-01-02",
-		time.Now().AddDate(0, 0, 7).Format("2006-01-02"),
-		time.Local)
+	defaultDueDate := util.EndOfDay(time.Now().AddDate(0, 0, 7))
 	var t = Task{
 		// Default due date is a week from now
 		Created:  time.Now(),
@@ -83,11 +105,21 @@ func newHandler(cmd *cli.Command, args []string) error {
 	var due = dueDate(defaultDueDate)
 	var priority = Priority(5)
 	var notes = false
+	var repeat Recurrence
+	var project string
+	var tags tagList
+	var estimate time.Duration
+	var template string
 
 	fs := flag.NewFlagSet("overlord tag new", flag.ContinueOnError)
 	fs.BoolVar(&notes, "notes", false, "edit notes")
 	fs.Var(&priority, "priority", "set priority (0-9)")
-	fs.Var(&due, "due", "due date (YYYY-MM-DD format)")
+	fs.Var(&due, "due", "due date")
+	fs.Var(&repeat, "repeat", "repeat rule")
+	fs.StringVar(&project, "project", "", "project name")
+	fs.Var(&tags, "tag", "comma separated tags")
+	fs.DurationVar(&estimate, "estimate", 0, "time estimate")
+	fs.StringVar(&template, "template", "", "template name")
 
 	// Discard output
 	fs.SetOutput(ioutil.Discard)
@@ -99,13 +131,45 @@ func newHandler(cmd *cli.Command, args []string) error {
 	}
 	log.Debug("Output from fs.Parse:", notes, due, priority, fs.Args())
 
+	if estimate < 0 {
+		return fmt.Errorf("Invalid estimate '%v'", estimate)
+	}
+
 	t.Description = strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if template != "" {
+		var tmpl Template
+		tmpl, err = loadTemplate(template)
+		if err != nil {
+			return err
+		}
+
+		err = tmpl.apply(&t, t.Description)
+		if err != nil {
+			return err
+		}
+	}
 	if len(t.Description) == 0 {
 		return fmt.Errorf("Missing task description")
 	}
 
-	t.Due = time.Time(due).Add(86399 * time.Second)
-	t.Priority = int(priority)
+	// Only the options that were given override the template
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "due":
+			t.Due = time.Time(due)
+		case "priority":
+			t.Priority = int(priority)
+		case "repeat":
+			t.Repeat = repeat
+		case "project":
+			t.Project = project
+		case "tag":
+			t.Tags = tags
+		case "estimate":
+			t.Estimate = estimate
+		}
+	})
+	t.Repeat = t.Repeat.anchor(t.Due)
 
 	if notes {
 		// Call the editor to edit the notes
@@ -0,0 +1,603 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/util"
+)
+
+// A filter expression is made up of terms of the form <field><op><value>,
+// which may be combined with "and", "or", "not" (or "!") and parentheses.
+// Adjacent terms are implicitly combined with "and". Values containing
+// spaces may be enclosed in double quotes.
+//
+// The following fields are supported
+//	state       in-progress, assigned, blocked, deferred, complete, obsolete
+//	pri         the task priority
+//	due         the due date
+//	created     the creation date
+//	wait        the date that the task is hidden until
+//	closed      the date that the task was completed or deleted
+//	desc        the task description
+//	project     the project name
+//	tag         the task tags
+//	id          the task ID
+//
+// The operators are ":" (is one of, or for dates, on that day), "=", "!=",
+// "<", "<=", ">", ">=", "~" (contains) and "!~" (does not contain). The
+// ":" and "!=" operators accept a comma separated list of values.
+//
+// A term without an operator is the name of a preset or saved filter.
+
+// query is a compiled filter expression
+type query func(t *Task) bool
+
+// presets are the built-in named filters, used by the task list types
+var presets = map[string]string{
+	"pending":     "state:in-progress,assigned,blocked wait<=now",
+	"waiting":     "state:in-progress,assigned,blocked,deferred wait>now",
+	"in-progress": "state:in-progress",
+	"overdue":     "pending due<=now",
+	"due":         "pending due>now due<+96h",
+	"blocked":     "state:blocked",
+	"deferred":    "state:deferred",
+	"completed":   "state:complete",
+	"deleted":     "state:obsolete",
+	"all":         "",
+}
+
+// savedFilterKey returns the settings key for a saved filter
+func savedFilterKey(name string) string {
+	return "task.filter." + name
+}
+
+// namedFilter returns the expression for a preset or saved filter
+func namedFilter(name string) (string, bool) {
+	if expr, ok := presets[name]; ok {
+		return expr, true
+	}
+
+	return config.Get(savedFilterKey(name))
+}
+
+// savedFilters returns a sorted list of the names of all saved filters
+func savedFilters() []string {
+	var names []string
+	prefix := savedFilterKey("")
+	for _, key := range config.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			names = append(names, strings.TrimPrefix(key, prefix))
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func matchAll(t *Task) bool {
+	return true
+}
+
+func andQuery(q1, q2 query) query {
+	return func(t *Task) bool {
+		return q1(t) && q2(t)
+	}
+}
+
+func orQuery(q1, q2 query) query {
+	return func(t *Task) bool {
+		return q1(t) || q2(t)
+	}
+}
+
+func notQuery(q query) query {
+	return func(t *Task) bool {
+		return !q(t)
+	}
+}
+
+// tokenize splits the expression into words and parentheses
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	var word strings.Builder
+	inWord := false
+	quoted := false
+
+	endWord := func() {
+		if inWord {
+			tokens = append(tokens, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for _, c := range expr {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inWord = true
+
+		case quoted:
+			word.WriteRune(c)
+
+		case c == ' ' || c == '\t' || c == '\n':
+			endWord()
+
+		case c == '(' || c == ')':
+			endWord()
+			tokens = append(tokens, string(c))
+
+		case c == '!' && !inWord:
+			// Negation at the start of a word
+			tokens = append(tokens, "!")
+
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("Unterminated quote in filter '%v'", expr)
+	}
+	endWord()
+
+	return tokens, nil
+}
+
+// queryParser is a recursive descent parser for filter expressions
+type queryParser struct {
+	tokens []string
+	pos    int
+	now    time.Time
+
+	// Names of the filters being expanded, to detect recursion
+	expanding map[string]bool
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+
+	return ""
+}
+
+func (p *queryParser) next() string {
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok
+}
+
+func (p *queryParser) parseOr() (query, error) {
+	q, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "or" {
+		p.next()
+		var rhs query
+		rhs, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		q = orQuery(q, rhs)
+	}
+
+	return q, nil
+}
+
+func (p *queryParser) parseAnd() (query, error) {
+	q, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek() {
+		case "", ")", "or":
+			return q, nil
+
+		case "and":
+			p.next()
+		}
+
+		var rhs query
+		rhs, err = p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		q = andQuery(q, rhs)
+	}
+}
+
+func (p *queryParser) parseUnary() (query, error) {
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("Unexpected end of filter")
+
+	case "not", "!":
+		p.next()
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery(q), nil
+
+	case "(":
+		p.next()
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("Missing ) in filter")
+		}
+		p.next()
+		return q, nil
+
+	case ")", "and", "or":
+		return nil, fmt.Errorf("Unexpected '%v' in filter", p.next())
+	}
+
+	return p.parseTerm(p.next())
+}
+
+// operators are the comparison operators, longest first so that "<=" is
+// matched before "<"
+var operators = []string{"<=", ">=", "!=", "!~", ":", "=", "<", ">", "~"}
+
+// splitTerm splits a term into the field, operator and value
+func splitTerm(term string) (field, op, value string) {
+	i := strings.IndexAny(term, ":=<>!~")
+	if i < 0 {
+		return term, "", ""
+	}
+
+	field = strings.ToLower(term[:i])
+	for _, op = range operators {
+		if strings.HasPrefix(term[i:], op) {
+			return field, op, term[i+len(op):]
+		}
+	}
+
+	return term, "", ""
+}
+
+func (p *queryParser) parseTerm(term string) (query, error) {
+	field, op, value := splitTerm(term)
+	if op == "" {
+		return p.expand(term)
+	}
+
+	switch field {
+	case "":
+		return nil, fmt.Errorf("Missing field in filter term '%v'", term)
+
+	case "state", "status":
+		return stateTerm(op, value)
+
+	case "pri", "priority":
+		return priorityTerm(op, value)
+
+	case "due":
+		return dateTerm(op, value, p.now, func(t *Task) time.Time { return t.Due })
+
+	case "created":
+		return dateTerm(op, value, p.now, func(t *Task) time.Time { return t.Created })
+
+	case "wait":
+		return dateTerm(op, value, p.now, func(t *Task) time.Time { return t.Wait })
+
+	case "closed":
+		q, err := dateTerm(op, value, p.now, func(t *Task) time.Time { return t.closedAt() })
+		if err != nil {
+			return nil, err
+		}
+		// Open tasks have no closing date, and never match
+		return func(t *Task) bool { return t.State >= Completed && q(t) }, nil
+
+	case "desc", "description":
+		return textTerm(op, value, func(t *Task) string { return t.Description })
+
+	case "project":
+		return textTerm(op, value, func(t *Task) string { return t.Project })
+
+	case "tag", "tags":
+		return tagTerm(op, value)
+
+	case "id":
+		return idTerm(op, value)
+	}
+
+	return nil, fmt.Errorf("Unknown field '%v' in filter term '%v'", field, term)
+}
+
+// expand parses the named filter
+func (p *queryParser) expand(name string) (query, error) {
+	expr, ok := namedFilter(name)
+	if !ok {
+		return nil, fmt.Errorf("Unknown filter '%v'", name)
+	}
+
+	if p.expanding[name] {
+		return nil, fmt.Errorf("Filter '%v' refers to itself", name)
+	}
+	p.expanding[name] = true
+	defer delete(p.expanding, name)
+
+	return p.parse(expr)
+}
+
+// parse parses a complete expression, using the parser state for expansion
+func (p *queryParser) parse(expr string) (query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return matchAll, nil
+	}
+
+	sub := queryParser{tokens: tokens, now: p.now, expanding: p.expanding}
+	q, err := sub.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.pos != len(sub.tokens) {
+		return nil, fmt.Errorf("Unexpected '%v' in filter", sub.next())
+	}
+
+	return q, nil
+}
+
+// parseQuery compiles the filter expression. An empty expression matches
+// all tasks.
+func parseQuery(expr string) (query, error) {
+	p := queryParser{now: time.Now(), expanding: make(map[string]bool)}
+	return p.parse(expr)
+}
+
+// parseState converts a state name into a State
+func parseState(name string) (State, error) {
+	switch strings.ToLower(name) {
+	case "completed":
+		return Completed, nil
+
+	case "deleted":
+		return Deleted, nil
+	}
+
+	for s := InProgress; s <= Deleted; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+
+	return InProgress, fmt.Errorf("Unknown task state '%v'", name)
+}
+
+func stateTerm(op, value string) (query, error) {
+	var states []State
+	for _, name := range strings.Split(value, ",") {
+		s, err := parseState(name)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+
+	in := func(t *Task) bool {
+		state := t.EffectiveState()
+		for _, s := range states {
+			if state == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case ":", "=":
+		return in, nil
+
+	case "!=":
+		return notQuery(in), nil
+	}
+
+	return nil, fmt.Errorf("Operator '%v' is not supported for state", op)
+}
+
+// compareInts returns the result of comparing a and b with the operator
+func compareInts(op string, a, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+
+	return a == b
+}
+
+func priorityTerm(op, value string) (query, error) {
+	var values []int
+	for _, v := range strings.Split(value, ",") {
+		pri, err := parsePriority(v)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, pri)
+	}
+
+	switch op {
+	case ":", "=", "!=":
+		return func(t *Task) bool {
+			for _, v := range values {
+				if t.Priority == v {
+					return op != "!="
+				}
+			}
+			return op == "!="
+		}, nil
+
+	case "<", "<=", ">", ">=":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("Operator '%v' takes a single priority", op)
+		}
+
+		return func(t *Task) bool {
+			return compareInts(op, t.Priority, values[0])
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Operator '%v' is not supported for priority", op)
+}
+
+// parseDateRange parses a date value in a filter, see util.ParseDate for the
+// accepted formats. A date without a time covers the entire day, while an
+// exact time has the same start and end.
+func parseDateRange(s string, now time.Time) (start, end time.Time, err error) {
+	var hasTime bool
+	start, hasTime, err = util.ParseDate(s, now)
+	if err != nil {
+		return start, end, err
+	}
+
+	end = start
+	if !hasTime {
+		end = util.EndOfDay(start)
+	}
+
+	return start, end, nil
+}
+
+func dateTerm(op, value string, now time.Time, field func(t *Task) time.Time) (query, error) {
+	start, end, err := parseDateRange(value, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var q query
+	switch op {
+	case "<":
+		q = func(t *Task) bool { return field(t).Before(start) }
+
+	case "<=":
+		q = func(t *Task) bool { return !field(t).After(end) }
+
+	case ">":
+		q = func(t *Task) bool { return field(t).After(end) }
+
+	case ">=":
+		q = func(t *Task) bool { return !field(t).Before(start) }
+
+	case ":", "=", "!=":
+		q = func(t *Task) bool {
+			return !field(t).Before(start) && !field(t).After(end)
+		}
+		if op == "!=" {
+			q = notQuery(q)
+		}
+
+	default:
+		return nil, fmt.Errorf("Operator '%v' is not supported for dates", op)
+	}
+
+	return q, nil
+}
+
+func textTerm(op, value string, field func(t *Task) string) (query, error) {
+	values := []string{value}
+	if op == ":" || op == "!=" {
+		values = strings.Split(value, ",")
+	}
+
+	switch op {
+	case ":", "=", "!=":
+		return func(t *Task) bool {
+			for _, v := range values {
+				if strings.EqualFold(field(t), v) {
+					return op != "!="
+				}
+			}
+			return op == "!="
+		}, nil
+
+	case "~", "!~":
+		value = strings.ToLower(value)
+		return func(t *Task) bool {
+			contains := strings.Contains(strings.ToLower(field(t)), value)
+			return contains == (op == "~")
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Operator '%v' is not supported for text", op)
+}
+
+func tagTerm(op, value string) (query, error) {
+	tags, err := parseTags([]string{value})
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case ":", "=":
+		return func(t *Task) bool { return t.HasTags(tags) }, nil
+
+	case "!=":
+		return func(t *Task) bool { return !t.HasTags(tags) }, nil
+	}
+
+	return nil, fmt.Errorf("Operator '%v' is not supported for tags", op)
+}
+
+func idTerm(op, value string) (query, error) {
+	ids := strings.Split(value, ",")
+
+	switch op {
+	case ":", "=", "!=":
+		return func(t *Task) bool {
+			for _, id := range ids {
+				if strings.HasPrefix(t.ID, strings.ToLower(id)) {
+					return op != "!="
+				}
+			}
+			return op == "!="
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Operator '%v' is not supported for IDs", op)
+}
+
+// validFilterName returns an error if the name cannot be used for a saved
+// filter
+func validFilterName(name string) error {
+	if _, ok := presets[name]; ok {
+		return fmt.Errorf("Cannot replace built-in filter '%v'", name)
+	}
+
+	if name == "" || strings.ContainsAny(name, " \t\"()!:=<>~") {
+		return fmt.Errorf("Invalid filter name '%v'", name)
+	}
+
+	switch strings.ToLower(name) {
+	case "and", "or", "not":
+		return fmt.Errorf("Invalid filter name '%v'", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTokenize verifies the splitting of filter expressions
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		expr string
+		exp  []string
+	}{
+		{"", nil},
+		{"pri<=3", []string{"pri<=3"}},
+		{"(a or b) c", []string{"(", "a", "or", "b", ")", "c"}},
+		{"!state:blocked", []string{"!", "state:blocked"}},
+		{"pri!=3", []string{"pri!=3"}},
+		{`desc~"foo (bar)"`, []string{"desc~foo (bar)"}},
+	}
+
+	for _, test := range tests {
+		tokens, err := tokenize(test.expr)
+		if err != nil {
+			t.Errorf("Tokenizing '%v', got error %v\n", test.expr, err)
+			continue
+		}
+
+		if len(tokens) != len(test.exp) {
+			t.Errorf("Tokenizing '%v', expected %q, got %q\n", test.expr, test.exp, tokens)
+			continue
+		}
+
+		for i := range tokens {
+			if tokens[i] != test.exp[i] {
+				t.Errorf("Tokenizing '%v', expected %q, got %q\n", test.expr, test.exp, tokens)
+				break
+			}
+		}
+	}
+}
+
+// TestQuery verifies that filter expressions select the right tasks
+func TestQuery(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 23, 59, 59, 0, time.Local)
+	}
+
+	task := Task{
+		ID:          "1a2b3c4d5e",
+		State:       InProgress,
+		Due:         day(20),
+		Priority:    2,
+		Description: "Deploy the new release",
+		Project:     "web",
+		Tags:        []string{"ops", "release"},
+	}
+
+	tests := []struct {
+		expr string
+		exp  bool
+	}{
+		{"", true},
+		{"pending", true},
+		{"completed", false},
+		{"state:in-progress,blocked", true},
+		{"state!=in-progress", false},
+		{"pri<=3", true},
+		{"pri>2", false},
+		{"pri:1,2", true},
+		{"due<2026-10-20", false},
+		{"due<=2026-10-20", true},
+		{"due:2026-10-20", true},
+		{"due>2026-10-19", true},
+		{"due>=2026-10-21", false},
+		{"desc~deploy", true},
+		{`desc~"new release"`, true},
+		{"desc!~deploy", false},
+		{"project:web", true},
+		{"project:api,web", true},
+		{"project!=web", false},
+		{"tag:ops", true},
+		{"tag!=ops", false},
+		{"id:1a2b", true},
+		{"pri<=3 desc~rollback", false},
+		{"pri<=3 and desc~rollback", false},
+		{"pri<=3 or desc~rollback", true},
+		{"not (pri<=3 or desc~rollback)", false},
+		{"!completed", true},
+		{"completed or (pending tag:ops)", true},
+	}
+
+	for _, test := range tests {
+		q, err := parseQuery(test.expr)
+		if err != nil {
+			t.Errorf("Parsing '%v', got error %v\n", test.expr, err)
+			continue
+		}
+
+		if q(&task) != test.exp {
+			t.Errorf("Filter '%v', expected %v, got %v\n", test.expr, test.exp, !test.exp)
+		}
+	}
+}
+
+// TestQueryErrors verifies that invalid expressions are rejected
+func TestQueryErrors(t *testing.T) {
+	tests := []string{
+		"(pri<3",
+		"pri<3)",
+		"and pri<3",
+		"pri<3 or",
+		"pri<x",
+		"pri:1,x",
+		"state:unknown",
+		"state<blocked",
+		"due<someday",
+		"color:red",
+		`desc~"unterminated`,
+	}
+
+	for _, expr := range tests {
+		_, err := parseQuery(expr)
+		if err == nil {
+			t.Errorf("Parsing '%v', expected an error\n", expr)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is the repeat rule for a task. It is stored in its canonical
+// string form, and an empty Recurrence means that the task does not repeat.
+//
+// The following rules are supported
+//
+//	daily               every day
+//	weekly              every week, on the same weekday as the due date
+//	weekly:mon,fri      every week, on the given weekdays
+//	monthly             every month, on the same day as the due date, which
+//	                    is saved as monthly:<day> when the rule is set
+//	monthly:15          every month, on the given day
+//	after:3             3 days after the task was completed
+type Recurrence string
+
+// repeatKind is the type of the repeat rule
+type repeatKind int
+
+const (
+	noRepeat repeatKind = iota
+	repeatDaily
+	repeatWeekly
+	repeatMonthly
+	repeatAfter
+)
+
+// repeatRule is the parsed form of a Recurrence
+type repeatRule struct {
+	kind     repeatKind
+	weekdays []time.Weekday
+	day      int
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for i, name := range weekdayNames {
+		if s == name || s == strings.ToLower(time.Weekday(i).String()) {
+			return time.Weekday(i), nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("Invalid weekday '%v'", s)
+}
+
+func parseRule(s string) (repeatRule, error) {
+	var rule repeatRule
+	var err error
+
+	kind := s
+	arg := ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		kind = s[:i]
+		arg = s[i+1:]
+	}
+
+	switch strings.ToLower(kind) {
+	case "", "none":
+		rule.kind = noRepeat
+
+	case "daily":
+		rule.kind = repeatDaily
+
+	case "weekly":
+		rule.kind = repeatWeekly
+		if arg != "" {
+			for _, name := range strings.Split(arg, ",") {
+				var day time.Weekday
+				day, err = parseWeekday(name)
+				if err != nil {
+					return rule, err
+				}
+				rule.weekdays = append(rule.weekdays, day)
+			}
+		}
+
+	case "monthly":
+		rule.kind = repeatMonthly
+		if arg != "" {
+			rule.day, err = strconv.Atoi(arg)
+			if err != nil || rule.day < 1 || rule.day > 31 {
+				return rule, fmt.Errorf("Day of month must be between 1 and 31, got '%v'", arg)
+			}
+		}
+
+	case "after":
+		rule.kind = repeatAfter
+		rule.day, err = strconv.Atoi(strings.TrimSuffix(arg, "d"))
+		if err != nil || rule.day < 1 {
+			return rule, fmt.Errorf("Repeat interval must be a positive number of days, got '%v'", arg)
+		}
+
+	default:
+		return rule, fmt.Errorf("Invalid repeat rule '%v'", s)
+	}
+
+	// Daily repeats don't take any arguments
+	if rule.kind == repeatDaily && arg != "" {
+		return rule, fmt.Errorf("Invalid repeat rule '%v'", s)
+	}
+
+	return rule, nil
+}
+
+// String returns the canonical form of the rule
+func (r repeatRule) String() string {
+	switch r.kind {
+	case repeatDaily:
+		return "daily"
+
+	case repeatWeekly:
+		if len(r.weekdays) == 0 {
+			return "weekly"
+		}
+
+		// Keep the weekdays in calendar order, without duplicates
+		var days []string
+		for i, name := range weekdayNames {
+			for _, day := range r.weekdays {
+				if day == time.Weekday(i) {
+					days = append(days, name)
+					break
+				}
+			}
+		}
+		return "weekly:" + strings.Join(days, ",")
+
+	case repeatMonthly:
+		if r.day == 0 {
+			return "monthly"
+		}
+		return fmt.Sprintf("monthly:%d", r.day)
+
+	case repeatAfter:
+		return fmt.Sprintf("after:%d", r.day)
+	}
+
+	return ""
+}
+
+// parseRecurrence validates the repeat rule and returns it in canonical form
+func parseRecurrence(s string) (Recurrence, error) {
+	rule, err := parseRule(s)
+	if err != nil {
+		return "", err
+	}
+
+	return Recurrence(rule.String()), nil
+}
+
+// anchor returns the rule tied to the day of the given due date. A plain
+// monthly rule would otherwise take the day from each due date in turn, and
+// stay on the 28th after February.
+func (r Recurrence) anchor(due time.Time) Recurrence {
+	rule, err := parseRule(string(r))
+	if err != nil || rule.kind != repeatMonthly || rule.day != 0 {
+		return r
+	}
+
+	rule.day = due.Day()
+	return Recurrence(rule.String())
+}
+
+// Set implements the flag.Value interface
+func (r *Recurrence) Set(s string) error {
+	rec, err := parseRecurrence(s)
+	if err == nil {
+		*r = rec
+	}
+
+	return err
+}
+
+func (r Recurrence) String() string {
+	return string(r)
+}
+
+// daysIn returns the number of days in the given month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local).Day()
+}
+
+// step advances the due date by a single occurrence of the rule
+func (r repeatRule) step(due time.Time) time.Time {
+	switch r.kind {
+	case repeatDaily:
+		return due.AddDate(0, 0, 1)
+
+	case repeatWeekly:
+		if len(r.weekdays) == 0 {
+			return due.AddDate(0, 0, 7)
+		}
+
+		for next := due.AddDate(0, 0, 1); ; next = next.AddDate(0, 0, 1) {
+			for _, day := range r.weekdays {
+				if next.Weekday() == day {
+					return next
+				}
+			}
+		}
+
+	case repeatMonthly:
+		day := r.day
+		if day == 0 {
+			day = due.Day()
+		}
+
+		// Move to the first of the next month before clamping the day, so
+		// that the 31st doesn't overflow into the month after
+		first := time.Date(due.Year(), due.Month()+1, 1,
+			due.Hour(), due.Minute(), due.Second(), 0, time.Local)
+		if max := daysIn(first.Year(), first.Month()); day > max {
+			day = max
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+
+	return due
+}
+
+// Next returns the due date of the next instance of a recurring task, given
+// the due date of the current instance and the time it was completed. The
+// time of day of the due date is preserved. Fixed schedules skip over any
+// occurrences that have already passed.
+func (r Recurrence) Next(due, completed time.Time) time.Time {
+	rule, err := parseRule(string(r))
+	if err != nil || rule.kind == noRepeat {
+		return due
+	}
+
+	if rule.kind == repeatAfter {
+		return time.Date(completed.Year(), completed.Month(),
+			completed.Day()+rule.day, due.Hour(), due.Minute(),
+			due.Second(), 0, time.Local)
+	}
+
+	next := rule.step(due)
+	for !next.After(completed) {
+		next = rule.step(next)
+	}
+
+	return next
+}
+
+// nextInstance creates the next instance of a recurring task. The new task
+// has a fresh creation time and ID, and starts out in the Assigned state.
+func (t *Task) nextInstance(now time.Time) Task {
+	next := Task{
+		Created:     now,
+		State:       Assigned,
+		Due:         t.Repeat.Next(t.Due, now),
+		Priority:    t.Priority,
+		Description: t.Description,
+		Notes:       t.Notes,
+		Repeat:      t.Repeat.anchor(t.Due),
+		Project:     t.Project,
+		Tags:        t.Tags,
+		Estimate:    t.Estimate,
+	}
+
+	// Start the checklist afresh for the next instance
+	for _, sub := range t.Subtasks {
+		next.Subtasks = append(next.Subtasks, Subtask{Text: sub.Text})
+	}
+
+	return next
+}
+
+// spawnNextInstance creates, saves and adds the next instance of a recurring
+// task to the database. The repeat rule moves to the next instance, so that
+// reopening and finishing the task again does not start a second series.
+// The caller is responsible for saving the task and the database.
+func spawnNextInstance(t *Task) error {
+	if t.Repeat == "" {
+		return nil
+	}
+
+	next := t.nextInstance(time.Now())
+	uniqueID(&next)
+	err := next.UpdatePath()
+	if err != nil {
+		return err
+	}
+
+	err = next.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(next)
+	fmt.Printf("Created next instance %v, due %v\n", next.ID,
+		next.dueString("Mon, Jan 2 2006"))
+
+	t.Repeat = ""
+	return nil
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseRecurrence verifies that repeat rules are validated and
+// normalized to their canonical form
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp Recurrence
+		err bool
+	}{
+		{"daily", "daily", false},
+		{"Daily", "daily", false},
+		{"weekly", "weekly", false},
+		{"weekly:fri,mon", "weekly:mon,fri", false},
+		{"weekly:Monday,mon", "weekly:mon", false},
+		{"monthly", "monthly", false},
+		{"monthly:15", "monthly:15", false},
+		{"after:3", "after:3", false},
+		{"after:3d", "after:3", false},
+		{"none", "", false},
+		{"", "", false},
+		{"daily:2", "", true},
+		{"weekly:xyz", "", true},
+		{"monthly:32", "", true},
+		{"after:0", "", true},
+		{"yearly", "", true},
+	}
+
+	for _, test := range tests {
+		rec, err := parseRecurrence(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("Parsing '%v', expected error %v, got %v\n", test.in, test.err, err)
+			continue
+		}
+
+		if rec != test.exp {
+			t.Errorf("Parsing '%v', expected '%v', got '%v'\n", test.in, test.exp, rec)
+		}
+	}
+}
+
+// TestRecurrenceNext verifies the due date computation for each rule
+func TestRecurrenceNext(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 23, 59, 59, 0, time.Local)
+	}
+
+	// 2026-10-12 is a Monday
+	tests := []struct {
+		rule      Recurrence
+		due       time.Time
+		completed time.Time
+		exp       time.Time
+	}{
+		{"daily", date(2026, 10, 12), date(2026, 10, 12), date(2026, 10, 13)},
+		{"daily", date(2026, 10, 12), date(2026, 10, 15), date(2026, 10, 16)},
+		{"weekly", date(2026, 10, 12), date(2026, 10, 12), date(2026, 10, 19)},
+		{"weekly:mon,fri", date(2026, 10, 12), date(2026, 10, 12), date(2026, 10, 16)},
+		{"weekly:mon,fri", date(2026, 10, 16), date(2026, 10, 16), date(2026, 10, 19)},
+		{"weekly:mon", date(2026, 10, 12), date(2026, 10, 10), date(2026, 10, 19)},
+		{"monthly", date(2026, 10, 12), date(2026, 10, 12), date(2026, 11, 12)},
+		{"monthly:31", date(2027, 1, 31), date(2027, 1, 31), date(2027, 2, 28)},
+		{"monthly:31", date(2027, 2, 28), date(2027, 2, 28), date(2027, 3, 31)},
+		{"after:3", date(2026, 10, 12), date(2026, 10, 20), date(2026, 10, 23)},
+	}
+
+	for _, test := range tests {
+		next := test.rule.Next(test.due, test.completed)
+		if !next.Equal(test.exp) {
+			t.Errorf("Rule %v from %v, expected %v, got %v\n", test.rule,
+				test.due, test.exp, next)
+		}
+	}
+
+	// A plain monthly rule keeps the day of the original due date, rather
+	// than drifting to the end of a short month
+	rule := Recurrence("monthly").anchor(date(2027, 1, 31))
+	due := date(2027, 1, 31)
+	for _, exp := range []time.Time{date(2027, 2, 28), date(2027, 3, 31), date(2027, 4, 30)} {
+		due = rule.Next(due, due)
+		if !due.Equal(exp) {
+			t.Errorf("Rule %v, expected %v, got %v\n", rule, exp, due)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"nirenjan.org/overlord/cli"
+)
+
+func registerReopenHandler(root *cli.Command) error {
+	// task reopen
+	cmd := cli.Cmd{
+		Command:   "reopen",
+		Usage:     "[-m reason] <id>",
+		BriefHelp: "reopen a completed or deleted task",
+		LongHelp: `
+Reopen a task that was completed or deleted, marking the task as
+assigned again. The time already worked on the task is kept. Tasks that
+were archived by "task cleanup" are restored from the archive. A
+recurring task no longer repeats once it has been completed, since the
+next instance carries on the series. This command accepts the following
+options
+
+	-m reason               The reason for reopening the task, which is
+	                        recorded in the task history
+`,
+		Handler: reopenHandler,
+		Args:    cli.AtLeast,
+		Count:   1,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+func reopenHandler(cmd *cli.Command, args []string) error {
+	var reason string
+
+	fs := flag.NewFlagSet("overlord task reopen", flag.ContinueOnError)
+	fs.StringVar(&reason, "m", "", "reason")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	pos, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		cmd.Usage()
+	}
+
+	err = LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(pos[0])
+	if _, ok := err.(ambiguousIDError); err != nil && !ok {
+		// Look for the task in the archive instead
+		task, err = findArchived(pos[0])
+		if err != nil {
+			return err
+		}
+
+		err = restoreTask(&task)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Restored", task.ID, "from the archive")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = task.reopen(reason)
+	if err != nil {
+		return err
+	}
+
+	err = saveTask(&task)
+	if err != nil {
+		return err
+	}
+
+	return SaveDb()
+}
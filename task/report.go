@@ -0,0 +1,363 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+func registerReportHandler(root *cli.Command) error {
+	var cmd cli.Cmd
+	var err error
+	var reportRoot *cli.Command
+	cmd = cli.Cmd{
+		Command:   "report",
+		Usage:     "...",
+		BriefHelp: "generate reports on tasks",
+		LongHelp:  "\nGenerate reports on the tasks in the database and the archive.\n",
+	}
+
+	reportRoot, err = cli.RegisterCommandGroup(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task report time
+	cmd = cli.Cmd{
+		Command:   "time",
+		Usage:     "[-by period] [-group field] [-from date] [-to date] [-format fmt]",
+		BriefHelp: "report the time worked on tasks",
+		LongHelp: `
+Report the time worked on tasks, from the recorded work sessions and any
+sessions that are still running, including tasks that have been archived.
+Sessions that span more than one period are split between them. This
+command accepts the following options
+
+	-by period              Total the time by day, week or month
+	                        (default day). Weeks start on Monday
+	-group field            Total the time by project, tag or task
+	                        (default project). A task with multiple tags
+	                        counts towards each of them, but only once
+	                        towards the totals
+	-from date              Start of the report (default the start of
+	                        the current week)
+	-to date                End of the report (default now)
+	-format fmt             Output as table, csv or json (default table)
+`,
+		Handler: timeReportHandler,
+		Args:    cli.Any,
+	}
+
+	_, err = cli.RegisterCommand(reportRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	return registerEstimateReport(reportRoot)
+}
+
+// parseReportRange parses the start and end dates of a report. The end of
+// the range is exclusive, so a date without a time includes the whole day.
+func parseReportRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+
+	if from != "" {
+		start, _, err = util.ParseDate(from, now)
+		if err != nil {
+			return start, end, err
+		}
+	}
+
+	end = now
+	if to != "" {
+		var hasTime bool
+		end, hasTime, err = util.ParseDate(to, now)
+		if err != nil {
+			return start, end, err
+		}
+
+		if !hasTime {
+			end = end.AddDate(0, 0, 1)
+		}
+	}
+
+	if !end.After(start) {
+		return start, end, fmt.Errorf("Report must end after it starts")
+	}
+
+	return start, end, nil
+}
+
+// periodStart returns the start of the day, week or month containing t
+func periodStart(t time.Time, by string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch by {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+
+	return day
+}
+
+// nextPeriod returns the start of the period following the one at start
+func nextPeriod(start time.Time, by string) time.Time {
+	switch by {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// periodLabel returns the label for the period starting at start
+func periodLabel(start time.Time, by string) string {
+	if by == "month" {
+		return start.Format("2006-01")
+	}
+
+	return start.Format("2006-01-02")
+}
+
+// allSessions returns the recorded work sessions for the task, as well as
+// the running session if the task is in progress
+func (t *Task) allSessions(now time.Time) []Session {
+	sessions := t.Sessions
+	if t.State == InProgress {
+		sessions = append(sessions[:len(sessions):len(sessions)],
+			Session{Start: t.Started, Stop: now})
+	}
+
+	return sessions
+}
+
+// reportGroups returns the groups that the task's time is counted under
+func (t *Task) reportGroups(group string) []string {
+	var groups []string
+	switch group {
+	case "project":
+		if t.Project != "" {
+			groups = append(groups, t.Project)
+		}
+
+	case "tag":
+		groups = append(groups, t.Tags...)
+
+	case "task":
+		groups = append(groups, t.ID+" "+t.Description)
+	}
+
+	if len(groups) == 0 {
+		groups = append(groups, "(none)")
+	}
+
+	return groups
+}
+
+// timeEntry is the total time worked in a single period and group
+type timeEntry struct {
+	Period string        `json:"period"`
+	Group  string        `json:"group"`
+	Time   time.Duration `json:"-"`
+	Hours  float64       `json:"hours"`
+}
+
+// timeReport totals the time worked on the tasks in the given range, by
+// period and group. It also returns the time worked in each period. Since
+// a task with several tags counts towards each of them, the period totals
+// may be less than the sum of the entries.
+func timeReport(tasks TaskList, start, end time.Time, by, group string) ([]timeEntry, map[string]time.Duration) {
+	now := time.Now()
+	totals := make(map[string]map[string]time.Duration)
+	periods := make(map[string]time.Duration)
+
+	for _, task := range tasks {
+		groups := task.reportGroups(group)
+		for _, s := range task.allSessions(now) {
+			if s.Start.Before(start) {
+				s.Start = start
+			}
+			if s.Stop.After(end) {
+				s.Stop = end
+			}
+
+			// Split the session at each period boundary
+			for s.Start.Before(s.Stop) {
+				period := periodStart(s.Start, by)
+				stop := nextPeriod(period, by)
+				if stop.After(s.Stop) {
+					stop = s.Stop
+				}
+
+				label := periodLabel(period, by)
+				if totals[label] == nil {
+					totals[label] = make(map[string]time.Duration)
+				}
+				for _, g := range groups {
+					totals[label][g] += stop.Sub(s.Start)
+				}
+				periods[label] += stop.Sub(s.Start)
+
+				s.Start = stop
+			}
+		}
+	}
+
+	var entries []timeEntry
+	for period, groups := range totals {
+		for g, d := range groups {
+			entries = append(entries, timeEntry{
+				Period: period,
+				Group:  g,
+				Time:   d,
+				Hours:  math.Round(d.Hours()*100) / 100,
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Period != entries[j].Period {
+			return entries[i].Period < entries[j].Period
+		}
+		return entries[i].Group < entries[j].Group
+	})
+
+	return entries, periods
+}
+
+// hoursString formats a duration as hours and minutes
+func hoursString(d time.Duration) string {
+	m := int64(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%d:%02d", m/60, m%60)
+}
+
+// showTimeReport displays the time report as a table. The totals are taken
+// from the time worked in each period, so that time counted towards more
+// than one group is only counted once.
+func showTimeReport(out io.Writer, entries []timeEntry, periods map[string]time.Duration, by, group string) {
+	fmt.Fprintf(out, "%-12v%-56v%10v\n", "Period", group, "Hours")
+	fmt.Fprintln(out, terminal.HorizontalLine())
+
+	var total time.Duration
+	for i, e := range entries {
+		period := e.Period
+		if i > 0 && entries[i-1].Period == e.Period {
+			period = ""
+		}
+
+		desc := e.Group
+		if len(desc) > 54 {
+			desc = desc[:51] + "..."
+		}
+		fmt.Fprintf(out, "%-12v%-56v%10v\n", period, desc, hoursString(e.Time))
+
+		if i == len(entries)-1 || entries[i+1].Period != e.Period {
+			fmt.Fprintf(out, "%-12v%-56v%10v\n\n", "", "Total for "+by, hoursString(periods[e.Period]))
+			total += periods[e.Period]
+		}
+	}
+
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	fmt.Fprintf(out, "%-68v%10v\n", "Total", hoursString(total))
+}
+
+func timeReportHandler(cmd *cli.Command, args []string) error {
+	var by, group, from, to, format string
+
+	fs := flag.NewFlagSet("overlord task report time", flag.ContinueOnError)
+	fs.StringVar(&by, "by", "day", "period")
+	fs.StringVar(&group, "group", "project", "group")
+	fs.StringVar(&from, "from", "", "start date")
+	fs.StringVar(&to, "to", "", "end date")
+	fs.StringVar(&format, "format", "table", "output format")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		cmd.Usage()
+	}
+
+	switch by {
+	case "day", "week", "month":
+	default:
+		return fmt.Errorf("Invalid period '%v'", by)
+	}
+
+	switch group {
+	case "project", "tag", "task":
+	default:
+		return fmt.Errorf("Invalid group '%v'", group)
+	}
+
+	now := time.Now()
+	if from == "" {
+		from = periodStart(now, "week").Format("2006-01-02")
+	}
+
+	var start, end time.Time
+	start, end, err = parseReportRange(from, to, now)
+	if err != nil {
+		return err
+	}
+
+	var tasks TaskList
+	tasks, err = reportTasks()
+	if err != nil {
+		return err
+	}
+
+	entries, periods := timeReport(tasks, start, end, by, group)
+
+	switch format {
+	case "table":
+		out := util.NewPager()
+		showTimeReport(out, entries, periods, by, group)
+		out.Show()
+
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"period", group, "hours"})
+		for _, e := range entries {
+			w.Write([]string{e.Period, e.Group,
+				strconv.FormatFloat(e.Hours, 'f', 2, 64)})
+		}
+		w.Flush()
+		return w.Error()
+
+	case "json":
+		if entries == nil {
+			entries = []timeEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("Invalid format '%v'", format)
+	}
+
+	return nil
+}
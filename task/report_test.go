@@ -0,0 +1,62 @@
+package task
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimeReportTags verifies that a task with several tags counts towards
+// each tag, but only once towards the totals
+func TestTimeReportTags(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.October, day, hour, 0, 0, 0, time.Local)
+	}
+
+	tasks := TaskList{
+		{
+			State:       Assigned,
+			Description: "Tagged twice",
+			Tags:        []string{"billing", "ops"},
+			Sessions:    []Session{{at(12, 9), at(12, 11), ""}},
+		},
+		{
+			State:       Assigned,
+			Description: "Untagged",
+			Sessions:    []Session{{at(12, 13), at(12, 14), ""}, {at(13, 9), at(13, 10), ""}},
+		},
+	}
+
+	entries, periods := timeReport(tasks, at(12, 0), at(14, 0), "day", "tag")
+
+	exp := map[string]time.Duration{
+		"2026-10-12 (none)":  time.Hour,
+		"2026-10-12 billing": 2 * time.Hour,
+		"2026-10-12 ops":     2 * time.Hour,
+		"2026-10-13 (none)":  time.Hour,
+	}
+	if len(entries) != len(exp) {
+		t.Errorf("Expected %v entries, got %v\n", len(exp), entries)
+	}
+	for _, e := range entries {
+		if exp[e.Period+" "+e.Group] != e.Time {
+			t.Errorf("Unexpected entry %+v\n", e)
+		}
+	}
+
+	if periods["2026-10-12"] != 3*time.Hour || periods["2026-10-13"] != time.Hour {
+		t.Errorf("Unexpected period totals %v\n", periods)
+	}
+
+	var out bytes.Buffer
+	showTimeReport(&out, entries, periods, "day", "tag")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if total := strings.Fields(lines[len(lines)-1]); total[len(total)-1] != "4:00" {
+		t.Errorf("Expected a total of 4:00, got '%v'\n", lines[len(lines)-1])
+	}
+	if !strings.Contains(out.String(), "Total for day") ||
+		!strings.Contains(out.String(), "3:00") {
+		t.Errorf("Expected a total of 3:00 for the first day\n%v", out.String())
+	}
+}
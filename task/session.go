@@ -0,0 +1,256 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+// Session is a single interval of time spent working on a task
+type Session struct {
+	Start   time.Time `json:"start"`
+	Stop    time.Time `json:"stop"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// Duration returns the length of the session
+func (s Session) Duration() time.Duration {
+	return s.Stop.Sub(s.Start)
+}
+
+func registerSessionHandler(root *cli.Command) error {
+	// task log
+	cmd := cli.Cmd{
+		Command:   "log",
+		Usage:     "<id>",
+		BriefHelp: "list the work sessions for a task",
+		LongHelp: `
+List every session of time spent working on a task, including the
+current one if the task is in progress.
+`,
+		Handler: logHandler,
+		Args:    cli.Exact,
+		Count:   1,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task track
+	cmd = cli.Cmd{
+		Command:   "track",
+		Usage:     "<id> <duration> [-at time] [-m comment] [-session N]",
+		BriefHelp: "add or correct a work session",
+		LongHelp: `
+Add a work session to a task, or correct an existing one. The duration
+is given as hours and minutes, for example 1h30m or 45m. This command
+accepts the following options
+
+	-at time                The start time of the session, for example
+	                        2026-10-16T09:00. Defaults to the duration
+	                        before now, or the existing start time when
+	                        correcting a session
+	-m comment              A comment for the session
+	-session N              Correct session N, as listed by "task log",
+	                        rather than adding a new one. A duration of 0
+	                        removes the session
+`,
+		Handler: trackHandler,
+		Args:    cli.AtLeast,
+		Count:   2,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// sessionString formats a session for display
+func sessionString(s Session, running bool) string {
+	stop := s.Stop.Format("15:04")
+	if running {
+		stop = "now  "
+	} else if s.Stop.Format("2006-01-02") != s.Start.Format("2006-01-02") {
+		stop = s.Stop.Format("Jan 2 15:04")
+	}
+
+	return fmt.Sprintf("%v - %-5v  %8v  %v", s.Start.Format("Mon, Jan 2 2006 15:04"),
+		stop, s.Duration().Round(time.Minute), s.Comment)
+}
+
+// ShowSessions displays the work sessions for the task
+func (t *Task) ShowSessions(out io.Writer) {
+	fmt.Fprintln(out, "Task:    ", t.Description)
+	fmt.Fprintln(out, terminal.HorizontalLine())
+
+	var total time.Duration
+	for i, s := range t.Sessions {
+		fmt.Fprintf(out, "%3d  %v\n", i+1, sessionString(s, false))
+		total += s.Duration()
+	}
+
+	if t.State == InProgress {
+		s := Session{Start: t.Started, Stop: time.Now()}
+		fmt.Fprintf(out, "%3v  %v\n", "*", sessionString(s, true))
+		total += s.Duration()
+	}
+
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	fmt.Fprintln(out, "Total:   ", total.Round(time.Minute))
+}
+
+func logHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(args[1])
+	if err != nil {
+		return err
+	}
+
+	out := util.NewPager()
+	task.ShowSessions(out)
+	out.Show()
+
+	return nil
+}
+
+// sortSessions sorts the sessions in chronological order
+func (t *Task) sortSessions() {
+	sort.SliceStable(t.Sessions, func(i, j int) bool {
+		return t.Sessions[i].Start.Before(t.Sessions[j].Start)
+	})
+}
+
+func trackHandler(cmd *cli.Command, args []string) error {
+	var at, comment string
+	var session int
+
+	fs := flag.NewFlagSet("overlord task track", flag.ContinueOnError)
+	fs.StringVar(&at, "at", "", "start time")
+	fs.StringVar(&comment, "m", "", "comment")
+	fs.IntVar(&session, "session", 0, "session to correct")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	pos, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		cmd.Usage()
+	}
+
+	var duration time.Duration
+	duration, err = time.ParseDuration(pos[1])
+	if err != nil || duration < 0 || (duration == 0 && session == 0) {
+		return fmt.Errorf("Invalid duration '%v'", pos[1])
+	}
+
+	err = LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(pos[0])
+	if err != nil {
+		return err
+	}
+
+	var s Session
+	if session != 0 {
+		if session < 1 || session > len(task.Sessions) {
+			return fmt.Errorf("Invalid session '%v'", session)
+		}
+		s = task.Sessions[session-1]
+		task.Worked -= s.Duration()
+	} else {
+		s.Start = time.Now().Add(-duration)
+	}
+
+	if at != "" {
+		s.Start, _, err = util.ParseDate(at, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	s.Stop = s.Start.Add(duration)
+	if comment != "" {
+		s.Comment = strings.TrimSpace(comment)
+	}
+
+	if session != 0 {
+		task.Sessions = append(task.Sessions[:session-1], task.Sessions[session:]...)
+	}
+	if duration != 0 {
+		task.Sessions = append(task.Sessions, s)
+		task.Worked += duration
+	}
+	task.sortSessions()
+
+	// Don't let corrections to sessions that predate the session log
+	// make the worked time go negative
+	if task.Worked < 0 {
+		task.Worked = 0
+	}
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+// parseSession parses a session from the task file, in the form
+// "<start> <stop> [comment]"
+func parseSession(value string) (Session, error) {
+	var s Session
+	var err error
+
+	fields := strings.SplitN(value, " ", 3)
+	if len(fields) < 2 {
+		return s, fmt.Errorf("Invalid session '%v'", value)
+	}
+
+	s.Start, err = time.ParseInLocation(time.RFC3339, fields[0], time.Local)
+	if err != nil {
+		return s, err
+	}
+
+	s.Stop, err = time.ParseInLocation(time.RFC3339, fields[1], time.Local)
+	if err != nil {
+		return s, err
+	}
+
+	if len(fields) == 3 {
+		s.Comment = fields[2]
+	}
+
+	return s, nil
+}
+
+// formatSession formats a session for the task file
+func formatSession(s Session) string {
+	return strings.TrimSpace(s.Start.Format(time.RFC3339) + " " +
+		s.Stop.Format(time.RFC3339) + " " + s.Comment)
+}
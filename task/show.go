@@ -1,6 +1,9 @@
 package task
 
 import (
+	"flag"
+	"io/ioutil"
+
 	"nirenjan.org/overlord/cli"
 	"nirenjan.org/overlord/util"
 )
@@ -9,15 +12,17 @@ func registerShowHandler(root *cli.Command) error {
 	// task show
 	cmd := cli.Cmd{
 		Command:   "show",
-		Usage:     "[id]",
+		Usage:     "[-sort keys] [-history] [id]",
 		BriefHelp: "show task detailed view",
 		LongHelp: `
 Show the detailed view of the specified task. If the ID is not specified,
-then show all pending tasks.
+then show all pending tasks, sorted by the given sort keys. See
+"task list -h" for the sort keys. If -history is given, then the state
+changes of each task are also shown, along with the time spent in each
+state and the reason for the change.
 `,
 		Handler: showHandler,
-		Args:    cli.AtMost,
-		Count:   1,
+		Args:    cli.Any,
 	}
 
 	_, err := cli.RegisterCommand(root, cmd)
@@ -34,12 +39,42 @@ func showHandler(cmd *cli.Command, args []string) error {
 		return err
 	}
 
+	var order string
+	var history bool
+
+	fs := flag.NewFlagSet("overlord task show", flag.ContinueOnError)
+	fs.StringVar(&order, "sort", "", "sort order")
+	fs.BoolVar(&history, "history", false, "show state history")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	var pos []string
+	pos, err = cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) > 1 {
+		cmd.Usage()
+	}
+
 	out := util.NewPager()
 	defer out.Show()
 
-	if len(args) == 1 {
+	show := func(task *Task) {
+		task.Show(out)
+		if history {
+			task.ShowHistory(out)
+		}
+	}
+
+	if len(pos) == 0 {
 		// Show all tasks
 		tasks := sortedTaskList()
+		err = tasks.sortBy(order)
+		if err != nil {
+			return err
+		}
 
 		for _, task := range tasks {
 			task, err = ReadFile(task.Path)
@@ -47,16 +82,16 @@ func showHandler(cmd *cli.Command, args []string) error {
 				return err
 			}
 
-			task.Show(out)
+			show(&task)
 		}
 	} else {
 		var task Task
-		task, err = getTask(args[1])
+		task, err = getTask(pos[0])
 		if err != nil {
 			return err
 		}
 
-		task.Show(out)
+		show(&task)
 	}
 
 	return nil
@@ -0,0 +1,128 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nirenjan.org/overlord/config"
+)
+
+// sortKey is a single key in a sort order
+type sortKey struct {
+	compare func(t1, t2 *Task) int
+	desc    bool
+}
+
+// compareInt returns -1, 0 or 1 depending on whether a is less than, equal
+// to, or greater than b
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// sortFields are the keys that tasks can be sorted on
+var sortFields = map[string]func(t1, t2 *Task) int{
+	"due": func(t1, t2 *Task) int {
+		return compareInt(t1.Due.Unix(), t2.Due.Unix())
+	},
+	"pri": func(t1, t2 *Task) int {
+		return compareInt(int64(t1.Priority), int64(t2.Priority))
+	},
+	"state": func(t1, t2 *Task) int {
+		return compareInt(int64(t1.EffectiveState()), int64(t2.EffectiveState()))
+	},
+	"created": func(t1, t2 *Task) int {
+		return compareInt(t1.Created.Unix(), t2.Created.Unix())
+	},
+	"desc": func(t1, t2 *Task) int {
+		return strings.Compare(strings.ToLower(t1.Description),
+			strings.ToLower(t2.Description))
+	},
+	"project": func(t1, t2 *Task) int {
+		return strings.Compare(t1.Project, t2.Project)
+	},
+	"id": func(t1, t2 *Task) int {
+		return strings.Compare(t1.ID, t2.ID)
+	},
+	// The most urgent tasks sort first, the same as for priority
+	"urgency": func(t1, t2 *Task) int {
+		u1, u2 := t1.Urgency(), t2.Urgency()
+		switch {
+		case u1 > u2:
+			return -1
+		case u1 < u2:
+			return 1
+		}
+		return 0
+	},
+}
+
+// parseSortOrder parses a comma separated list of sort keys. Each key may be
+// prefixed with - to sort in descending order.
+func parseSortOrder(order string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, name := range strings.Split(order, ",") {
+		var key sortKey
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "-") {
+			key.desc = true
+			name = name[1:]
+		}
+
+		switch name {
+		case "priority":
+			name = "pri"
+		case "description":
+			name = "desc"
+		}
+
+		var ok bool
+		key.compare, ok = sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid sort key '%v'", name)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// sortBy sorts the task list by the given sort order. If the order is empty,
+// then the order in the "task.sort" setting is used, and if that isn't set
+// either, the default ordering is used. Tasks that are equal on all the keys
+// are also sorted using the default ordering.
+func (tl TaskList) sortBy(order string) error {
+	if order == "" {
+		order = config.String("task.sort", "")
+	}
+
+	if order == "" {
+		sort.Sort(tl)
+		return nil
+	}
+
+	keys, err := parseSortOrder(order)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(tl, func(i, j int) bool {
+		for _, key := range keys {
+			c := key.compare(&tl[i], &tl[j])
+			if c != 0 {
+				return (c < 0) != key.desc
+			}
+		}
+
+		return tl.Less(i, j)
+	})
+
+	return nil
+}
@@ -1,16 +1,24 @@
 package task
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
 	"nirenjan.org/overlord/cli"
 )
 
+// stateOptionsHelp describes the options for the state transition commands
+const stateOptionsHelp = bulkHelp + `	-m reason               The reason for the change, which is recorded
+	                        in the task history
+`
+
 func registerStateTransitionHandler(root *cli.Command) error {
 	// Generic state transition handler
 	cmd := cli.Cmd{
-		Usage:   "<id>",
+		Usage:   "[-m reason] [-filter expr] [-y] [id ...]",
 		Handler: stateTransitionHandler,
-		Args:    cli.Exact,
-		Count:   1,
+		Args:    cli.Any,
 	}
 
 	// task start
@@ -19,8 +27,9 @@ func registerStateTransitionHandler(root *cli.Command) error {
 	cmd.LongHelp = `
 Start working on a task, marking the task state as in-progress.
 Evil Overlord will keep track of the time a task spends in the
-in-progress state.
-`
+in-progress state. If the task.single-active setting is true, the task
+may not be started while another task is in progress.
+` + stateOptionsHelp
 	_, err := cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -31,7 +40,7 @@ in-progress state.
 	cmd.BriefHelp = "stop working on a task"
 	cmd.LongHelp = `
 Stop working on a task, marking the task state as paused.
-`
+` + stateOptionsHelp
 	_, err = cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -43,7 +52,8 @@ Stop working on a task, marking the task state as paused.
 	cmd.LongHelp = `
 Mark a task as completed. This is a terminal state, and you may not
 change the state of the task once you have marked it as completed.
-`
+If the task repeats, the next instance is created automatically.
+` + stateOptionsHelp
 	_, err = cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -55,7 +65,7 @@ change the state of the task once you have marked it as completed.
 	cmd.LongHelp = `
 Mark a task as deleted. This is a terminal state, and you may not
 change the state of the task once you have marked it as deleted.
-`
+` + stateOptionsHelp
 	_, err = cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -65,9 +75,11 @@ change the state of the task once you have marked it as deleted.
 	cmd.Command = "block"
 	cmd.BriefHelp = "mark a task as blocked"
 	cmd.LongHelp = `
-Mark a task as blocked on something. You may use the notes
-to add info on why the task is blocked.
-`
+Mark a task as blocked on something. You may give the reason with
+-m, which is recorded in the task history (see "task show -history").
+Tasks that are waiting on other tasks (see "task depend") are treated
+as blocked automatically.
+` + stateOptionsHelp
 	_, err = cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -77,9 +89,9 @@ to add info on why the task is blocked.
 	cmd.Command = "defer"
 	cmd.BriefHelp = "mark a task as deferred"
 	cmd.LongHelp = `
-Mark a task as deferred for later. You may use the notes to add info
-on why the task is deferred.
-`
+Mark a task as deferred for later. You may give the reason with -m,
+which is recorded in the task history (see "task show -history").
+` + stateOptionsHelp
 	_, err = cli.RegisterCommand(root, cmd)
 	if err != nil {
 		return err
@@ -89,48 +101,79 @@ on why the task is deferred.
 }
 
 func stateTransitionHandler(cmd *cli.Command, args []string) error {
-	err := LoadDb()
+	var reason string
+	var bulk bulkOptions
+
+	fs := flag.NewFlagSet("overlord task "+args[0], flag.ContinueOnError)
+	fs.StringVar(&reason, "m", "", "reason")
+	bulk.addFlags(fs)
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	pos, err := cli.ParseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 && bulk.filter == "" {
+		cmd.Usage()
+	}
+
+	err = LoadDb()
 	if err != nil {
 		return err
 	}
 
-	var task Task
-	task, err = getTask(args[1])
+	var ids []string
+	ids, pos, err = bulk.selectTasks(pos, 0)
 	if err != nil {
 		return err
 	}
+	if len(pos) != 0 {
+		return fmt.Errorf("Task %v not found", pos[0])
+	}
 
 	var newState State
+	var verb string
 	switch args[0] {
 	case "delete":
 		newState = Deleted
+		verb = "Delete"
 
 	case "start":
 		newState = InProgress
+		verb = "Start"
 
 	case "stop":
 		newState = Assigned
+		verb = "Stop"
 
 	case "block":
 		newState = Blocked
+		verb = "Block"
 
 	case "defer":
 		newState = Deferred
+		verb = "Defer"
 
 	case "finish":
 		newState = Completed
+		verb = "Finish"
 	}
 
-	err = task.stateTransition(newState)
-	if err != nil {
-		return err
-	}
-
-	err = task.Write()
-	if err != nil {
-		return err
-	}
-
-	AddDbEntry(task)
-	return SaveDb()
+	return bulk.apply(verb, ids, func(task *Task) error {
+		err := task.stateTransition(newState, reason)
+		if err != nil {
+			return err
+		}
+
+		if newState == Completed {
+			err = spawnNextInstance(task)
+			if err != nil {
+				return err
+			}
+		}
+
+		return saveTask(task)
+	})
 }
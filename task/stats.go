@@ -0,0 +1,252 @@
+package task
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+func registerStatsHandler(root *cli.Command) error {
+	// task stats
+	cmd := cli.Cmd{
+		Command:   "stats",
+		Usage:     "[-from date] [-to date]",
+		BriefHelp: "show task throughput and cycle time statistics",
+		LongHelp: `
+Show statistics on the tasks over a date range, including the number of
+tasks created and completed each week, the lead time from creating a
+task to completing it, the time spent in each state, and how many tasks
+were completed after they were due. This command accepts the following
+options
+
+	-from date              Start of the range (default 8 weeks ago)
+	-to date                End of the range (default now)
+
+Archived tasks are included. Tasks that were completed before state
+changes were recorded in the task history are not included in the
+completion statistics.
+`,
+		Handler: statsHandler,
+		Args:    cli.Any,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+// sparkline returns a line of bars representing the values, scaled to the
+// largest value
+func sparkline(values []int) string {
+	bars := []rune("▁▂▃▄▅▆▇█")
+
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var line []rune
+	for _, v := range values {
+		if max == 0 || v == 0 {
+			line = append(line, '·')
+			continue
+		}
+		line = append(line, bars[(v*(len(bars)-1)+max-1)/max])
+	}
+
+	return string(line)
+}
+
+// daysString formats a long duration in days, and a short one in hours and
+// minutes
+func daysString(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%.1f days", d.Hours()/24)
+	}
+
+	return hoursString(d)
+}
+
+// median returns the median of the durations
+func median(durations []time.Duration) time.Duration {
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	n := len(durations)
+	if n%2 == 1 {
+		return durations[n/2]
+	}
+
+	return (durations[n/2-1] + durations[n/2]) / 2
+}
+
+// taskStats holds the statistics for the tasks over a date range
+type taskStats struct {
+	start, end time.Time
+
+	weeks     []time.Time
+	created   []int
+	completed []int
+
+	leadTimes []time.Duration
+	late      int
+	overdue   int
+
+	states map[State]time.Duration
+}
+
+// computeStats calculates the statistics for the given tasks
+func computeStats(tasks TaskList, start, end time.Time) *taskStats {
+	stats := &taskStats{
+		start:  start,
+		end:    end,
+		states: make(map[State]time.Duration),
+	}
+
+	for week := periodStart(start, "week"); week.Before(end); week = nextPeriod(week, "week") {
+		stats.weeks = append(stats.weeks, week)
+	}
+	stats.created = make([]int, len(stats.weeks))
+	stats.completed = make([]int, len(stats.weeks))
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(start) && t.Before(end)
+	}
+	weekIndex := func(t time.Time) int {
+		return int(periodStart(t, "week").Sub(stats.weeks[0]).Hours()+12) / (7 * 24)
+	}
+
+	for _, task := range tasks {
+		if inRange(task.Created) {
+			stats.created[weekIndex(task.Created)]++
+		}
+
+		if done := task.completedAt(); inRange(done) {
+			stats.completed[weekIndex(done)]++
+			stats.leadTimes = append(stats.leadTimes, done.Sub(task.Created))
+			if done.After(task.Due) {
+				stats.late++
+			}
+		}
+
+		if task.State < Completed && task.Due.Before(end) {
+			stats.overdue++
+		}
+
+		for state, d := range task.stateDurations(start, end) {
+			stats.states[state] += d
+		}
+	}
+
+	return stats
+}
+
+// Show displays the statistics
+func (s *taskStats) Show(out io.Writer) {
+	fmt.Fprintf(out, "Tasks from %v to %v\n", s.start.Format("Mon, Jan 2 2006"),
+		s.end.Add(-time.Second).Format("Mon, Jan 2 2006"))
+	fmt.Fprintln(out, terminal.HorizontalLine())
+
+	fmt.Fprintf(out, "%-14v%9v%11v\n", "Week of", "Created", "Completed")
+	var created, completed int
+	for i, week := range s.weeks {
+		fmt.Fprintf(out, "%-14v%9v%11v\n", week.Format("2006-01-02"), s.created[i], s.completed[i])
+		created += s.created[i]
+		completed += s.completed[i]
+	}
+	fmt.Fprintf(out, "%-14v%9v%11v\n", "Total", created, completed)
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "%-14v%v\n", "Created", sparkline(s.created))
+	fmt.Fprintf(out, "%-14v%v\n", "Completed", sparkline(s.completed))
+	fmt.Fprintln(out, terminal.HorizontalLine())
+
+	fmt.Fprintln(out, "Lead time, from creating to completing a task")
+	if n := len(s.leadTimes); n != 0 {
+		leadTimes := append([]time.Duration(nil), s.leadTimes...)
+		m := median(leadTimes)
+		fmt.Fprintf(out, "    %-20v%v\n", "Median", daysString(m))
+		fmt.Fprintf(out, "    %-20v%v\n", "Shortest", daysString(leadTimes[0]))
+		fmt.Fprintf(out, "    %-20v%v\n", "Longest", daysString(leadTimes[n-1]))
+	} else {
+		fmt.Fprintln(out, "    No tasks completed")
+	}
+	fmt.Fprintln(out, "")
+
+	fmt.Fprintln(out, "Overdue")
+	if n := len(s.leadTimes); n != 0 {
+		fmt.Fprintf(out, "    %-20v%v of %v (%.0f%%)\n", "Completed late", s.late, n,
+			100*float64(s.late)/float64(n))
+	}
+	fmt.Fprintf(out, "    %-20v%v\n", "Open and overdue", s.overdue)
+	fmt.Fprintln(out, "")
+
+	fmt.Fprintln(out, "Time in each state")
+	var max time.Duration
+	for _, d := range s.states {
+		if d > max {
+			max = d
+		}
+	}
+	width := terminal.Width() - 40
+	for state := InProgress; state <= Deferred; state++ {
+		d := s.states[state]
+		bar := ""
+		if max != 0 && width > 0 {
+			bar = strings.Repeat("█", int(int64(width)*int64(d)/int64(max)))
+		}
+		fmt.Fprintf(out, "    %-20v%-14v%v\n", state, daysString(d), bar)
+	}
+	fmt.Fprintln(out, terminal.HorizontalLine())
+}
+
+func statsHandler(cmd *cli.Command, args []string) error {
+	var from, to string
+
+	fs := flag.NewFlagSet("overlord task stats", flag.ContinueOnError)
+	fs.StringVar(&from, "from", "", "start date")
+	fs.StringVar(&to, "to", "", "end date")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		cmd.Usage()
+	}
+
+	now := time.Now()
+	if from == "" {
+		from = periodStart(now, "week").AddDate(0, 0, -7*7).Format("2006-01-02")
+	}
+
+	var start, end time.Time
+	start, end, err = parseReportRange(from, to, now)
+	if err != nil {
+		return err
+	}
+
+	var tasks TaskList
+	tasks, err = reportTasks()
+	if err != nil {
+		return err
+	}
+
+	out := util.NewPager()
+	computeStats(tasks, start, end).Show(out)
+	out.Show()
+
+	return nil
+}
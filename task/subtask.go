@@ -0,0 +1,170 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nirenjan.org/overlord/cli"
+)
+
+// Subtask is a single checklist item within a task
+type Subtask struct {
+	Done bool   `json:"done"`
+	Text string `json:"text"`
+}
+
+func registerSubtaskHandler(root *cli.Command) error {
+	var cmd cli.Cmd
+	var err error
+	var subRoot *cli.Command
+	cmd = cli.Cmd{
+		Command:   "sub",
+		Usage:     "...",
+		BriefHelp: "manage task checklist items",
+		LongHelp: `
+Manage the checklist items within a task. Items are numbered in the
+order that they were added, starting from 1. Set "task.open-subtasks"
+to "refuse" to prevent completing a task with open checklist items,
+the default is to warn about them.
+`,
+	}
+
+	subRoot, err = cli.RegisterCommandGroup(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task sub add
+	cmd = cli.Cmd{
+		Command:   "add",
+		Usage:     "<id> <text>",
+		BriefHelp: "add a checklist item",
+		LongHelp:  "\nAdd a checklist item to the end of the list.\n",
+		Handler:   subtaskHandler,
+		Args:      cli.AtLeast,
+		Count:     2,
+	}
+
+	_, err = cli.RegisterCommand(subRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task sub done
+	cmd = cli.Cmd{
+		Command:   "done",
+		Usage:     "<id> <item> [<item> ...]",
+		BriefHelp: "check off checklist items",
+		LongHelp:  "\nMark one or more checklist items as done.\n",
+		Handler:   subtaskHandler,
+		Args:      cli.AtLeast,
+		Count:     2,
+	}
+
+	_, err = cli.RegisterCommand(subRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task sub rm
+	cmd = cli.Cmd{
+		Command:   "rm",
+		Usage:     "<id> <item> [<item> ...]",
+		BriefHelp: "remove checklist items",
+		LongHelp:  "\nRemove one or more checklist items.\n",
+		Handler:   subtaskHandler,
+		Args:      cli.AtLeast,
+		Count:     2,
+	}
+
+	_, err = cli.RegisterCommand(subRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseSubtaskIndices converts the 1-based item numbers to 0-based indices,
+// sorted in descending order so that items can be removed in place.
+func (t *Task) parseSubtaskIndices(items []string) ([]int, error) {
+	var indices []int
+	for _, item := range items {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 1 || n > len(t.Subtasks) {
+			return nil, fmt.Errorf("Invalid checklist item '%v'", item)
+		}
+		indices = append(indices, n-1)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(indices)))
+	return indices, nil
+}
+
+func subtaskHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(args[1])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		text := strings.TrimSpace(strings.Join(args[2:], " "))
+		if len(text) == 0 {
+			return fmt.Errorf("Invalid checklist item")
+		}
+
+		task.Subtasks = append(task.Subtasks, Subtask{Text: text})
+
+	case "done":
+		indices, err := task.parseSubtaskIndices(args[2:])
+		if err != nil {
+			return err
+		}
+
+		for _, i := range indices {
+			task.Subtasks[i].Done = true
+		}
+
+	case "rm":
+		indices, err := task.parseSubtaskIndices(args[2:])
+		if err != nil {
+			return err
+		}
+
+		for n, i := range indices {
+			// Skip duplicates, the indices are sorted
+			if n > 0 && indices[n-1] == i {
+				continue
+			}
+			task.Subtasks = append(task.Subtasks[:i], task.Subtasks[i+1:]...)
+		}
+	}
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+// subtaskProgress returns the number of completed and total checklist items
+func (t *Task) subtaskProgress() (done, total int) {
+	for _, sub := range t.Subtasks {
+		if sub.Done {
+			done++
+		}
+	}
+
+	return done, len(t.Subtasks)
+}
@@ -0,0 +1,154 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+func registerSwitchHandler(root *cli.Command) error {
+	// task switch
+	cmd := cli.Cmd{
+		Command:   "switch",
+		Usage:     "<id>",
+		BriefHelp: "stop the current task and start another",
+		LongHelp: `
+Stop working on every task that is currently in progress, and start
+working on the given task, in a single step.
+
+To prevent more than one task from being in progress at the same time,
+so that the same time isn't counted towards several tasks, set
+
+	overlord config set task.single-active true
+
+"task start" will then refuse to start a task while another task is in
+progress.
+`,
+		Handler: switchHandler,
+		Args:    cli.Exact,
+		Count:   1,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task current
+	cmd = cli.Cmd{
+		Command:   "current",
+		Usage:     " ",
+		BriefHelp: "show the tasks in progress",
+		LongHelp: `
+Show the tasks that are currently in progress, and the time elapsed
+since each of them was started.
+`,
+		Handler: currentHandler,
+		Args:    cli.None,
+	}
+
+	_, err = cli.RegisterCommand(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func switchHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	task, err = getTask(args[1])
+	if err != nil {
+		return err
+	}
+
+	if task.State == InProgress {
+		return fmt.Errorf("Task %v is already in progress", task.ID)
+	}
+
+	// Stop the active tasks, but don't write anything until the new task
+	// has been started successfully
+	var stopped []Task
+	for _, id := range task.otherActiveTasks() {
+		var active Task
+		active, err = getTask(id)
+		if err != nil {
+			return err
+		}
+
+		err = active.stateTransition(Assigned, "switched to "+task.ID)
+		if err != nil {
+			return err
+		}
+
+		stopped = append(stopped, active)
+		AddDbEntry(active)
+	}
+
+	err = task.stateTransition(InProgress, "")
+	if err != nil {
+		return err
+	}
+
+	for _, active := range stopped {
+		err = active.Write()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Stopped %v %v\n", active.ID, active.Description)
+	}
+
+	err = task.Write()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Started %v %v\n", task.ID, task.Description)
+
+	AddDbEntry(task)
+	return SaveDb()
+}
+
+func currentHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var tasks TaskList
+	for _, task := range DB {
+		if task.State == InProgress {
+			tasks = append(tasks, task)
+		}
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println("No tasks in progress")
+		return nil
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Started.Before(tasks[j].Started)
+	})
+
+	out := util.NewPager()
+	fmt.Fprintf(out, "%-12v%-9v%-9v%10v  %v\n", "ID", "Started", "Elapsed", "Worked", "Description")
+	fmt.Fprintln(out, terminal.HorizontalLine())
+	for _, task := range tasks {
+		elapsed := time.Since(task.Started)
+		fmt.Fprintf(out, "%-12v%-9v%-9v%10v  %v\n", task.ID,
+			task.Started.Format("15:04"), hoursString(elapsed),
+			hoursString(task.Worked+elapsed), task.Description)
+	}
+	out.Show()
+
+	return nil
+}
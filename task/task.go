@@ -3,9 +3,15 @@ package task
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+
+	"nirenjan.org/overlord/log"
+	"nirenjan.org/overlord/undo"
 )
 
 func ReadFile(path string) (Task, error) {
@@ -19,6 +25,7 @@ func ReadFile(path string) (Task, error) {
 
 	scanner := bufio.NewScanner(f)
 	line := 0
+	inNotes := false
 	for scanner.Scan() {
 		text := scanner.Text()
 		switch line {
@@ -56,7 +63,15 @@ func ReadFile(path string) (Task, error) {
 			task.Description = text
 
 		default:
-			task.Notes += text + "\n"
+			if !inNotes && strings.HasPrefix(text, "#") {
+				err = task.readAttr(text[1:])
+				if err != nil {
+					return task, err
+				}
+			} else {
+				inNotes = true
+				task.Notes += text + "\n"
+			}
 		}
 
 		line++
@@ -70,7 +85,62 @@ func ReadFile(path string) (Task, error) {
 	return task, nil
 }
 
+// checkLines returns an error if any of the fields that are stored on a
+// single line of the task file contain a line break. Writing such a field
+// would turn the rest of the line into notes or attributes. Tags are also
+// checked for spaces, since they are stored separated by spaces.
+func (t *Task) checkLines() error {
+	check := func(name, value string) error {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%v must be a single line", name)
+		}
+		return nil
+	}
+
+	err := check("Description", t.Description)
+	if err == nil {
+		err = check("Project", t.Project)
+	}
+	for _, tag := range t.Tags {
+		if err == nil && strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+			err = fmt.Errorf("Tag '%v' may not contain spaces", tag)
+		}
+	}
+	for _, sub := range t.Subtasks {
+		if err == nil {
+			err = check("Checklist item", sub.Text)
+		}
+	}
+	for _, a := range t.Annotations {
+		if err == nil {
+			err = check("Annotation", a.Text)
+		}
+	}
+	for _, tr := range t.History {
+		if err == nil {
+			err = check("Reason", tr.Reason)
+		}
+	}
+	for _, s := range t.Sessions {
+		if err == nil {
+			err = check("Session comment", s.Comment)
+		}
+	}
+
+	return err
+}
+
 func (t *Task) Write() error {
+	err := t.checkLines()
+	if err != nil {
+		return err
+	}
+
+	err = undo.Record(t.Path)
+	if err != nil {
+		return err
+	}
+
 	file, err := os.Create(t.Path)
 	if err != nil {
 		return err
@@ -90,7 +160,122 @@ func (t *Task) Write() error {
 	file.WriteString("\n")
 	file.WriteString(t.Description)
 	file.WriteString("\n")
+	t.writeAttrs(file)
 	file.WriteString(t.Notes)
 
 	return nil
 }
+
+// Extended attributes are stored one per line between the description and
+// the notes, in the form "#key value". EditNotes strips all lines beginning
+// with #, so they can never be confused with the notes themselves.
+
+// readAttr parses a single extended attribute line into the task
+func (t *Task) readAttr(line string) error {
+	var err error
+	key := line
+	value := ""
+	if i := strings.Index(line, " "); i >= 0 {
+		key = line[:i]
+		value = line[i+1:]
+	}
+
+	switch key {
+	case "repeat":
+		t.Repeat, err = parseRecurrence(value)
+
+	case "depends":
+		t.Depends = strings.Fields(value)
+
+	case "project":
+		t.Project = value
+
+	case "tags":
+		t.Tags = strings.Fields(value)
+
+	case "wait":
+		t.Wait, err = time.ParseInLocation(time.RFC3339, value, time.Local)
+
+	case "history":
+		var tr Transition
+		tr, err = parseTransition(value)
+		if err == nil {
+			t.History = append(t.History, tr)
+		}
+
+	case "annotation":
+		var a Annotation
+		a, err = parseAnnotation(value)
+		if err == nil {
+			t.Annotations = append(t.Annotations, a)
+		}
+
+	case "estimate":
+		t.Estimate, err = time.ParseDuration(value)
+
+	case "focus":
+		t.Focus, err = strconv.Atoi(value)
+
+	case "session":
+		var s Session
+		s, err = parseSession(value)
+		if err == nil {
+			t.Sessions = append(t.Sessions, s)
+		}
+
+	case "subtask":
+		if len(value) < 4 || value[0] != '[' || value[2] != ']' {
+			return fmt.Errorf("Invalid checklist item '%v' in %v", value, t.Path)
+		}
+		t.Subtasks = append(t.Subtasks, Subtask{
+			Done: value[1] == 'x',
+			Text: value[4:],
+		})
+
+	default:
+		log.Warning("Ignoring unknown task attribute", key, "in", t.Path)
+	}
+
+	return err
+}
+
+// writeAttrs writes all the extended attributes that are set on the task
+func (t *Task) writeAttrs(out io.StringWriter) {
+	if t.Repeat != "" {
+		out.WriteString(fmt.Sprintf("#repeat %v\n", t.Repeat))
+	}
+	if len(t.Depends) != 0 {
+		out.WriteString(fmt.Sprintf("#depends %v\n", strings.Join(t.Depends, " ")))
+	}
+	if t.Project != "" {
+		out.WriteString(fmt.Sprintf("#project %v\n", t.Project))
+	}
+	if len(t.Tags) != 0 {
+		out.WriteString(fmt.Sprintf("#tags %v\n", strings.Join(t.Tags, " ")))
+	}
+	if !t.Wait.IsZero() {
+		out.WriteString(fmt.Sprintf("#wait %v\n", t.Wait.Format(time.RFC3339)))
+	}
+	for _, sub := range t.Subtasks {
+		mark := " "
+		if sub.Done {
+			mark = "x"
+		}
+		out.WriteString(fmt.Sprintf("#subtask [%v] %v\n", mark, sub.Text))
+	}
+	if t.Estimate != 0 {
+		out.WriteString(fmt.Sprintf("#estimate %v\n", t.Estimate))
+	}
+	if t.Focus != 0 {
+		out.WriteString(fmt.Sprintf("#focus %v\n", t.Focus))
+	}
+	for _, a := range t.Annotations {
+		out.WriteString(fmt.Sprintf("#annotation %v %v\n", a.Time.Format(time.RFC3339), a.Text))
+	}
+	for _, tr := range t.History {
+		out.WriteString(fmt.Sprintf("#history %v\n", formatTransition(tr)))
+	}
+	for _, s := range t.Sessions {
+		out.WriteString(fmt.Sprintf("#session %v\n", formatSession(s)))
+	}
+}
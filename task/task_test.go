@@ -0,0 +1,120 @@
+package task
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tempDataDir points the data directory at a new temporary directory, to
+// keep the tests out of the user's data. It returns the directory, and a
+// function that restores the environment and removes the directory.
+func tempDataDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "overlord-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, set := os.LookupEnv("OVERLORD_DATA")
+	os.Setenv("OVERLORD_DATA", dir)
+
+	return dir, func() {
+		if set {
+			os.Setenv("OVERLORD_DATA", old)
+		} else {
+			os.Unsetenv("OVERLORD_DATA")
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// sameTask returns true if the tasks are the same, ignoring the time zone
+// locations, which are not preserved in the task file
+func sameTask(t1, t2 Task) bool {
+	j1, err1 := json.Marshal(t1)
+	j2, err2 := json.Marshal(t2)
+	return err1 == nil && err2 == nil && string(j1) == string(j2) &&
+		t1.ID == t2.ID && t1.Path == t2.Path
+}
+
+// TestTaskFile verifies that a task with all of the attributes set reads
+// back the same as it was written
+func TestTaskFile(t *testing.T) {
+	// Keep the undo log out of the user's data directory
+	dir, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.October, day, hour, 0, 0, 0, time.Local)
+	}
+
+	task := Task{
+		Created:     at(1, 9),
+		Due:         at(8, 17),
+		Priority:    3,
+		State:       InProgress,
+		Started:     at(5, 10),
+		Worked:      90 * time.Minute,
+		Description: "Write the release notes",
+		Notes:       "First line\n\nSecond line\n",
+		Repeat:      "monthly:1",
+		Depends:     []string{"1a2b3c4d5e"},
+		Subtasks:    []Subtask{{true, "Draft"}, {false, "Review"}},
+		Project:     "web",
+		Tags:        []string{"docs", "release"},
+		Wait:        at(2, 0),
+		Sessions:    []Session{{at(3, 10), at(3, 11), "focus"}, {at(4, 10), at(4, 10).Add(30 * time.Minute), ""}},
+		Focus:       1,
+		Estimate:    2 * time.Hour,
+		Annotations: []Annotation{{at(3, 12), "Waiting on the changelog"}},
+		History: []Transition{
+			{Assigned, InProgress, at(3, 10), ""},
+			{InProgress, Assigned, at(3, 11), "lunch"},
+			{Assigned, InProgress, at(5, 10), ""},
+		},
+		Path: filepath.Join(dir, "test.task"),
+	}
+	task.UpdateID()
+
+	err := task.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadFile(task.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sameTask(task, read) {
+		t.Errorf("Task changed when read back\nwrote: %+v\nread:  %+v\n", task, read)
+	}
+
+	// Line breaks in single line fields, or spaces in tags, would corrupt
+	// the file
+	bad := []func(t *Task){
+		func(t *Task) { t.Description = "two\nlines" },
+		func(t *Task) { t.Annotations[0].Text = "line1\nline2" },
+		func(t *Task) { t.History[1].Reason = "line1\rline2" },
+		func(t *Task) { t.Sessions[0].Comment = "line1\nline2" },
+		func(t *Task) { t.Subtasks[0].Text = "line1\nline2" },
+		func(t *Task) { t.Tags = []string{"two words"} },
+	}
+
+	for i, change := range bad {
+		changed, _ := ReadFile(task.Path)
+		change(&changed)
+		if changed.Write() == nil {
+			t.Errorf("Case %v: expected an error writing the task\n", i)
+		}
+	}
+
+	// The file must not have been changed by the failed writes
+	read, err = ReadFile(task.Path)
+	if err != nil || !sameTask(task, read) {
+		t.Errorf("Task file changed by a failed write (%v)\n", err)
+	}
+}
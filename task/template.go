@@ -0,0 +1,436 @@
+package task
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/undo"
+	"nirenjan.org/overlord/util"
+)
+
+// Templates are stored one per file under task/templates, in the same form
+// as the task attributes, one "#key value" per line, followed by the notes.
+// Lines beginning with "# " are comments, and any other lines beginning
+// with # in the notes are ignored. Any {} in the template is replaced by
+// the value given to "task new -template".
+
+// Template is a named set of defaults for new tasks
+type Template struct {
+	Name        string
+	Description string
+	Priority    int
+	Due         string
+	Project     string
+	Tags        []string
+	Estimate    time.Duration
+	Checklist   []string
+	Notes       string
+}
+
+// templateSkeleton is the initial contents of a new template
+const templateSkeleton = `# Task Template - %v
+#
+# Set the task fields with the lines below, and remove the ones that are
+# not needed. Any {} is replaced by the value given to "task new -template".
+# The due date is relative to the day the task is created, such as +3d or
+# friday. Add a "#subtask" line for each checklist item. Everything after
+# the fields is used as the notes.
+#description {}
+#priority 5
+#due +1w
+#project
+#tags
+#estimate
+#subtask
+`
+
+func registerTemplateHandler(root *cli.Command) error {
+	// task template
+	cmd := cli.Cmd{
+		Command:   "template",
+		Usage:     "...",
+		BriefHelp: "manage task templates",
+		LongHelp: `
+Manage templates for tasks that are created the same way every time,
+such as release or onboarding checklists. A template sets the
+description, priority, relative due date, project, tags, estimate,
+checklist and notes of the new task. Create a task from a template with
+"task new -template name value", where the value replaces any {} in the
+template.
+`,
+		Subcommand: "Template Commands",
+	}
+
+	templateRoot, err := cli.RegisterCommandGroup(root, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task template list
+	cmd = cli.Cmd{
+		Command:   "list",
+		Usage:     " ",
+		BriefHelp: "list the task templates",
+		LongHelp:  "\nList the names and descriptions of the task templates.\n",
+		Handler:   templateHandler,
+		Args:      cli.None,
+	}
+
+	_, err = cli.RegisterCommand(templateRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task template show
+	cmd = cli.Cmd{
+		Command:   "show",
+		Usage:     "<name>",
+		BriefHelp: "show a task template",
+		LongHelp:  "\nShow the fields, checklist and notes of the task template.\n",
+		Handler:   templateHandler,
+		Args:      cli.Exact,
+		Count:     1,
+	}
+
+	_, err = cli.RegisterCommand(templateRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task template edit
+	cmd = cli.Cmd{
+		Command:   "edit",
+		Usage:     "<name>",
+		BriefHelp: "create or edit a task template",
+		LongHelp: `
+Open the task template in the editor, creating it if it does not exist
+yet. New templates start with a description of each of the fields.
+`,
+		Handler: templateHandler,
+		Args:    cli.Exact,
+		Count:   1,
+	}
+
+	_, err = cli.RegisterCommand(templateRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	// task template rm
+	cmd = cli.Cmd{
+		Command:   "rm",
+		Usage:     "<name>",
+		BriefHelp: "remove a task template",
+		LongHelp:  "\nRemove the task template with the given name.\n",
+		Handler:   templateHandler,
+		Args:      cli.Exact,
+		Count:     1,
+	}
+
+	_, err = cli.RegisterCommand(templateRoot, cmd)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// templatePath returns the path to the file for the named template
+func templatePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, " \t/\\") || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("Invalid template name '%v'", name)
+	}
+
+	dir, err := config.ModuleDir("task", "templates")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name+".tmpl"), nil
+}
+
+// parseTemplate parses the contents of a template file
+func parseTemplate(name, data string) (Template, error) {
+	tmpl := Template{Name: name, Priority: 5}
+
+	var err error
+	inNotes := false
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		text := scanner.Text()
+		if !strings.HasPrefix(text, "#") {
+			inNotes = true
+			tmpl.Notes += text + "\n"
+			continue
+		}
+
+		// Lines beginning with # would be read back as task attributes, so
+		// they are dropped from the notes, the same as in EditNotes
+		if inNotes {
+			continue
+		}
+
+		// Comments
+		if text == "#" || strings.HasPrefix(text, "# ") {
+			continue
+		}
+
+		key := text[1:]
+		value := ""
+		if i := strings.Index(key, " "); i >= 0 {
+			key, value = key[:i], strings.TrimSpace(key[i+1:])
+		}
+
+		// Fields without a value are left at their defaults
+		if value == "" {
+			continue
+		}
+
+		switch key {
+		case "description":
+			tmpl.Description = value
+
+		case "priority":
+			tmpl.Priority, err = parsePriority(value)
+
+		case "due":
+			// Make sure the date is valid, but keep it relative
+			_, err = parseDue(value)
+			tmpl.Due = value
+
+		case "project":
+			tmpl.Project = value
+
+		case "tags":
+			tmpl.Tags, err = parseTags(strings.Fields(value))
+
+		case "estimate":
+			tmpl.Estimate, err = parseEstimate(value)
+
+		case "subtask":
+			tmpl.Checklist = append(tmpl.Checklist, value)
+
+		default:
+			err = fmt.Errorf("Unknown field '%v'", key)
+		}
+
+		if err != nil {
+			return tmpl, fmt.Errorf("Template %v: %v", name, err)
+		}
+	}
+
+	if tmpl.Description == "" {
+		return tmpl, fmt.Errorf("Template %v: missing description", name)
+	}
+
+	// Drop the blank lines between the fields and the notes
+	tmpl.Notes = strings.TrimLeft(tmpl.Notes, "\n")
+	return tmpl, scanner.Err()
+}
+
+// loadTemplate reads the named template
+func loadTemplate(name string) (Template, error) {
+	path, err := templatePath(name)
+	if err != nil {
+		return Template{}, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Template{}, fmt.Errorf("Template %v not found", name)
+	}
+	if err != nil {
+		return Template{}, err
+	}
+
+	return parseTemplate(name, string(data))
+}
+
+// templateNames returns the names of all the templates, in sorted order
+func templateNames() ([]string, error) {
+	dir, err := config.ModuleDir("task", "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".tmpl"))
+	}
+
+	return names, nil
+}
+
+// apply sets the fields of the task from the template, replacing {} with
+// the value. The value is appended to the description if it has no {}.
+func (tmpl *Template) apply(t *Task, value string) error {
+	expand := func(s string) string {
+		return strings.ReplaceAll(s, "{}", value)
+	}
+
+	switch {
+	case strings.Contains(tmpl.Description, "{}") && value == "":
+		return fmt.Errorf("Template %v needs a value for the description", tmpl.Name)
+
+	case strings.Contains(tmpl.Description, "{}"):
+		t.Description = expand(tmpl.Description)
+
+	default:
+		t.Description = strings.TrimSpace(tmpl.Description + " " + value)
+	}
+
+	t.Priority = tmpl.Priority
+	if tmpl.Due != "" {
+		due, err := parseDue(tmpl.Due)
+		if err != nil {
+			return err
+		}
+		t.Due = due
+	}
+
+	t.Project = expand(tmpl.Project)
+	t.Tags = append([]string(nil), tmpl.Tags...)
+	t.Estimate = tmpl.Estimate
+	t.Notes = expand(tmpl.Notes)
+
+	t.Subtasks = nil
+	for _, item := range tmpl.Checklist {
+		t.Subtasks = append(t.Subtasks, Subtask{Text: expand(item)})
+	}
+
+	return nil
+}
+
+func templateHandler(cmd *cli.Command, args []string) error {
+	switch args[0] {
+	case "list":
+		names, err := templateNames()
+		if err != nil {
+			return err
+		}
+
+		out := util.NewPager()
+		fmt.Fprintf(out, "%-20v%v\n", "Template", "Description")
+		fmt.Fprintln(out, terminal.HorizontalLine())
+		for _, name := range names {
+			tmpl, err := loadTemplate(name)
+			if err != nil {
+				fmt.Fprintf(out, "%-20v(%v)\n", name, err)
+				continue
+			}
+			fmt.Fprintf(out, "%-20v%v\n", name, tmpl.Description)
+		}
+		out.Show()
+
+	case "show":
+		tmpl, err := loadTemplate(args[1])
+		if err != nil {
+			return err
+		}
+
+		out := util.NewPager()
+		tmpl.Show(out)
+		out.Show()
+
+	case "edit":
+		return editTemplate(args[1])
+
+	case "rm":
+		path, err := templatePath(args[1])
+		if err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("Template %v not found", args[1])
+		}
+
+		err = undo.Record(path)
+		if err != nil {
+			return err
+		}
+
+		return os.Remove(path)
+	}
+
+	return nil
+}
+
+// Show displays the template
+func (tmpl *Template) Show(out io.Writer) {
+	fmt.Fprintln(out, "Template:", tmpl.Name)
+	fmt.Fprintln(out, "Task:    ", tmpl.Description)
+	if tmpl.Due != "" {
+		fmt.Fprintln(out, "Due:     ", tmpl.Due)
+	}
+	fmt.Fprintln(out, "Priority:", tmpl.Priority)
+	if tmpl.Project != "" {
+		fmt.Fprintln(out, "Project: ", tmpl.Project)
+	}
+	if len(tmpl.Tags) != 0 {
+		fmt.Fprintln(out, "Tags:    ", strings.Join(tmpl.Tags, " "))
+	}
+	if tmpl.Estimate != 0 {
+		fmt.Fprintln(out, "Estimate:", hoursString(tmpl.Estimate))
+	}
+
+	if len(tmpl.Checklist) != 0 {
+		fmt.Fprintln(out, "Checklist:")
+		for i, item := range tmpl.Checklist {
+			fmt.Fprintf(out, "    %2d. [ ] %v\n", i+1, item)
+		}
+	}
+
+	if tmpl.Notes != "" {
+		fmt.Fprintln(out, terminal.HorizontalLine())
+		fmt.Fprint(out, tmpl.Notes)
+	}
+}
+
+// editTemplate opens the named template in the editor, creating it first
+// if necessary, and checks that it is still valid afterwards
+func editTemplate(name string) error {
+	path, err := templatePath(name)
+	if err != nil {
+		return err
+	}
+
+	err = undo.Record(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		skeleton := fmt.Sprintf(templateSkeleton, name)
+		err = ioutil.WriteFile(path, []byte(skeleton), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = util.Editor(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = loadTemplate(name)
+	if err != nil {
+		return fmt.Errorf("%v, fix it with \"task template edit %v\"", err, name)
+	}
+
+	return nil
+}
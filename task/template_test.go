@@ -0,0 +1,64 @@
+package task
+
+import (
+	"testing"
+)
+
+// TestTemplate verifies parsing a template and creating a task from it
+func TestTemplate(t *testing.T) {
+	data := `# Release checklist
+#description Release {}
+#priority 2
+#project
+#tags release,ops
+#subtask Tag {}
+#subtask Announce {}
+
+Notes for {}
+`
+
+	tmpl, err := parseTemplate("release", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var task Task
+	err = tmpl.apply(&task, "v1.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Description != "Release v1.4" || task.Priority != 2 || task.Project != "" {
+		t.Errorf("Unexpected task %+v\n", task)
+	}
+	if len(task.Tags) != 2 || task.Tags[1] != "ops" {
+		t.Errorf("Expected tags [release ops], got %v\n", task.Tags)
+	}
+	if len(task.Subtasks) != 2 || task.Subtasks[0].Text != "Tag v1.4" {
+		t.Errorf("Unexpected checklist %v\n", task.Subtasks)
+	}
+	if task.Notes != "Notes for v1.4\n" {
+		t.Errorf("Unexpected notes %q\n", task.Notes)
+	}
+
+	err = tmpl.apply(&task, "")
+	if err == nil {
+		t.Errorf("Expected an error for a missing value\n")
+	}
+
+	// Notes must not start with a line that reads back as an attribute
+	tmpl, err = parseTemplate("notes", "#description x\n\n#wait later\nNotes\n# done\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Notes != "Notes\n" {
+		t.Errorf("Unexpected notes %q\n", tmpl.Notes)
+	}
+
+	for _, bad := range []string{"#priority 2\n", "#description x\n#colour red\n", "#description x\n#due whenever\n"} {
+		_, err = parseTemplate("bad", bad)
+		if err == nil {
+			t.Errorf("Parsing %q, expected an error\n", bad)
+		}
+	}
+}
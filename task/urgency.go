@@ -0,0 +1,182 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/util"
+)
+
+// The urgency of a task is the weighted sum of a number of factors, each of
+// which is scaled to the range 0 to 1. The weights may be changed with the
+// "task.urgency.<factor>" settings.
+var urgencyWeights = map[string]float64{
+	"priority": 6.0,  // Priority 0 is 1.0, priority 9 is 0.0
+	"due":      12.0, // Scales from 0.2 two weeks out to 1.0 a week overdue
+	"age":      2.0,  // Scales up to 1.0 at a year old
+	"active":   4.0,  // The task is in progress
+	"worked":   1.0,  // Scales up to 1.0 at 8 hours worked
+	"blocking": 8.0,  // Other tasks are waiting on this one
+	"blocked":  -5.0, // The task is blocked
+	"deferred": -3.0, // The task is deferred
+	"waiting":  -3.0, // The task is hidden until its wait date
+}
+
+// urgencyWeight returns the configured weight for the urgency factor
+func urgencyWeight(factor string) float64 {
+	return config.Float("task.urgency."+factor, urgencyWeights[factor])
+}
+
+// isBlocking returns true if any unfinished tasks depend on this task
+func (t *Task) isBlocking() bool {
+	for _, task := range DB {
+		if task.State >= Completed {
+			continue
+		}
+
+		for _, dep := range task.Depends {
+			if dep == t.ID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Urgency returns the urgency score for the task. Completed and deleted
+// tasks have no urgency.
+func (t *Task) Urgency() float64 {
+	state := t.EffectiveState()
+	if state >= Completed {
+		return 0
+	}
+
+	now := time.Now()
+	var urgency float64
+
+	urgency += urgencyWeight("priority") * float64(9-t.Priority) / 9
+
+	// Due dates more than 2 weeks out are at the minimum, and tasks that
+	// are more than a week overdue are at the maximum
+	daysOverdue := now.Sub(t.Due).Hours() / 24
+	due := 0.2
+	if daysOverdue >= 7 {
+		due = 1.0
+	} else if daysOverdue >= -14 {
+		due = (daysOverdue+14)*0.8/21 + 0.2
+	}
+	urgency += urgencyWeight("due") * due
+
+	age := now.Sub(t.Created).Hours() / (365 * 24)
+	if age > 1 {
+		age = 1
+	}
+	urgency += urgencyWeight("age") * age
+
+	worked := t.Worked
+	if state == InProgress {
+		worked += time.Since(t.Started)
+	}
+	workedHours := worked.Hours() / 8
+	if workedHours > 1 {
+		workedHours = 1
+	}
+	urgency += urgencyWeight("worked") * workedHours
+
+	switch state {
+	case InProgress:
+		urgency += urgencyWeight("active")
+
+	case Blocked:
+		urgency += urgencyWeight("blocked")
+
+	case Deferred:
+		urgency += urgencyWeight("deferred")
+	}
+
+	if t.isWaiting() {
+		urgency += urgencyWeight("waiting")
+	}
+
+	if t.isBlocking() {
+		urgency += urgencyWeight("blocking")
+	}
+
+	return urgency
+}
+
+func registerNextHandler(root *cli.Command) error {
+	// task next
+	cmd := cli.Cmd{
+		Command:   "next",
+		Usage:     " ",
+		BriefHelp: "show the most urgent task",
+		LongHelp: `
+Show the most urgent task that can be worked on right now, i.e., one
+that is either in progress or assigned, is not waiting on any other
+tasks, and is not hidden until a later date.
+
+The urgency of a task is computed from its priority, due date, age,
+state, the time already worked on it, and whether it is blocked by or
+blocking other tasks. The weight of each of these may be changed with
+the following settings
+
+	task.urgency.priority   Priority (default 6.0)
+	task.urgency.due        Due date (default 12.0)
+	task.urgency.age        Time since the task was created (default 2.0)
+	task.urgency.active     Task is in progress (default 4.0)
+	task.urgency.worked     Time worked on the task (default 1.0)
+	task.urgency.blocking   Other tasks depend on the task (default 8.0)
+	task.urgency.blocked    Task is blocked (default -5.0)
+	task.urgency.deferred   Task is deferred (default -3.0)
+	task.urgency.waiting    Task is waiting (default -3.0)
+`,
+		Handler: nextHandler,
+		Args:    cli.None,
+	}
+
+	_, err := cli.RegisterCommand(root, cmd)
+	return err
+}
+
+func nextHandler(cmd *cli.Command, args []string) error {
+	err := LoadDb()
+	if err != nil {
+		return err
+	}
+
+	var next *Task
+	var max float64
+	for _, task := range DB {
+		state := task.EffectiveState()
+		if (state != InProgress && state != Assigned) || task.isWaiting() {
+			continue
+		}
+
+		urgency := task.Urgency()
+		if next == nil || urgency > max {
+			task := task
+			next = &task
+			max = urgency
+		}
+	}
+
+	if next == nil {
+		return fmt.Errorf("No tasks to work on")
+	}
+
+	var task Task
+	task, err = getTask(next.ID)
+	if err != nil {
+		return err
+	}
+
+	out := util.NewPager()
+	task.Show(out)
+	out.Show()
+
+	return nil
+}
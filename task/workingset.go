@@ -0,0 +1,104 @@
+package task
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nirenjan.org/overlord/config"
+)
+
+// The working set is the list of tasks shown by the last "task list", so
+// that they may be referred to by their position in the list, rather than
+// their IDs.
+
+// workingSetFile returns the path to the file holding the working set
+func workingSetFile() (string, error) {
+	dir, err := config.ModuleDir("task")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "workingset"), nil
+}
+
+// saveWorkingSet saves the IDs of the listed tasks as the working set
+func saveWorkingSet(ids []string) error {
+	path, err := workingSetFile()
+	if err != nil {
+		return err
+	}
+
+	data := strings.Join(ids, "\n")
+	return ioutil.WriteFile(path, []byte(data), 0644)
+}
+
+// loadWorkingSet returns the IDs of the tasks in the working set
+func loadWorkingSet() []string {
+	path, err := workingSetFile()
+	if err != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	return strings.Fields(string(data))
+}
+
+// ambiguousIDError is returned when an ID prefix matches more than one task
+type ambiguousIDError struct {
+	id         string
+	candidates []string
+}
+
+func (e ambiguousIDError) Error() string {
+	return fmt.Sprintf("Ambiguous ID %v, candidates: %v", e.id,
+		strings.Join(e.candidates, ", "))
+}
+
+// resolveID returns the full ID of the task referred to by id, which may be
+// the full ID, a number from the working set, or a unique prefix of the ID.
+func resolveID(id string) (string, error) {
+	if _, ok := DB[id]; ok {
+		return id, nil
+	}
+
+	// A number in the working set may refer to a task that is no longer
+	// in the database, such as an archived task, rather than a prefix
+	if n, err := strconv.Atoi(id); err == nil && n > 0 {
+		ws := loadWorkingSet()
+		if n <= len(ws) {
+			if _, ok := DB[ws[n-1]]; !ok {
+				return "", fmt.Errorf("Task %v not found", id)
+			}
+			return ws[n-1], nil
+		}
+	}
+
+	prefix := strings.ToLower(id)
+	var candidates []string
+	if prefix != "" {
+		for full := range DB {
+			if strings.HasPrefix(full, prefix) {
+				candidates = append(candidates, full)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("Task %v not found", id)
+
+	case 1:
+		return candidates[0], nil
+	}
+
+	sort.Strings(candidates)
+	return "", ambiguousIDError{id, candidates}
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"testing"
+)
+
+// TestResolveID verifies the resolution of full and partial task IDs
+func TestResolveID(t *testing.T) {
+	saved := DB
+	defer func() { DB = saved }()
+
+	// Keep the working set lookup out of the user's data directory
+	_, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	DB = map[string]Task{
+		"1a2b3c4d5e": {},
+		"1a2f000000": {},
+		"9c8b7a6d5e": {},
+	}
+
+	tests := []struct {
+		id  string
+		exp string
+	}{
+		{"1a2b3c4d5e", "1a2b3c4d5e"},
+		{"1a2b", "1a2b3c4d5e"},
+		{"1a2f", "1a2f000000"},
+		{"9", "9c8b7a6d5e"},
+		{"9C8B", "9c8b7a6d5e"},
+	}
+
+	for _, test := range tests {
+		id, err := resolveID(test.id)
+		if err != nil || id != test.exp {
+			t.Errorf("Resolving '%v', expected %v, got %v (%v)\n", test.id, test.exp, id, err)
+		}
+	}
+
+	_, err := resolveID("1a2")
+	if _, ok := err.(ambiguousIDError); !ok {
+		t.Errorf("Resolving '1a2', expected an ambiguous ID error, got %v\n", err)
+	}
+
+	for _, id := range []string{"ff", ""} {
+		_, err = resolveID(id)
+		if err == nil {
+			t.Errorf("Resolving '%v', expected an error\n", id)
+		}
+	}
+
+	// Numbers in the working set take precedence over prefixes, even when
+	// the task is no longer in the database
+	DB["2d00000000"] = Task{}
+	err = saveWorkingSet([]string{"9c8b7a6d5e", "0000000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := resolveID("1")
+	if err != nil || id != "9c8b7a6d5e" {
+		t.Errorf("Resolving '1', expected 9c8b7a6d5e, got %v (%v)\n", id, err)
+	}
+
+	_, err = resolveID("2")
+	if err == nil {
+		t.Errorf("Resolving '2', expected an error\n")
+	}
+}
@@ -95,6 +95,17 @@ func Underline() string {
 	return csi("3m")
 }
 
+// ClearLine sends the sequence to clear the current line, and returns the
+// cursor to the start of the line
+func ClearLine() string {
+	return csi("2K") + "\r"
+}
+
+// Width returns the current width of the terminal
+func Width() int {
+	return termcols
+}
+
 // HorizontalLine prints a horizontal line spanning the width of the terminal
 func HorizontalLine() string {
 	return strings.Repeat("-", termcols)
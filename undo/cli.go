@@ -0,0 +1,99 @@
+package undo
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"nirenjan.org/overlord/cli"
+	"nirenjan.org/overlord/module"
+	"nirenjan.org/overlord/terminal"
+	"nirenjan.org/overlord/util"
+)
+
+func init() {
+	mod := module.Module{Name: "undo"}
+
+	mod.Callbacks[module.BuildCommandTree] = func() error {
+		cmd := cli.Cmd{
+			Command:   "undo",
+			Usage:     "[-n count] [-list]",
+			BriefHelp: "undo the most recent changes",
+			LongHelp: `
+Undo the changes made by the most recent Overlord commands, such as
+changing the state of a task, editing a task, or adding or deleting a
+journal entry. The changes are undone in the reverse order that they
+were made. Up to 50 commands are kept. This command accepts the
+following options
+
+	-n count                Undo the given number of commands (default 1)
+	-list                   List the commands that may be undone, most
+	                        recent first, instead of undoing anything
+`,
+			Handler: undoHandler,
+			Args:    cli.Any,
+		}
+
+		// Register the command at the root level
+		_, err := cli.RegisterCommand(nil, cmd)
+		return err
+	}
+
+	module.RegisterModule(mod)
+}
+
+func undoHandler(cmd *cli.Command, args []string) error {
+	var count int
+	var list bool
+
+	fs := flag.NewFlagSet("overlord undo", flag.ContinueOnError)
+	fs.IntVar(&count, "n", 1, "number of commands")
+	fs.BoolVar(&list, "list", false, "list commands")
+
+	// Discard output
+	fs.SetOutput(ioutil.Discard)
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || count < 1 {
+		cmd.Usage()
+	}
+
+	ops, err := Operations()
+	if err != nil {
+		return err
+	}
+
+	if list {
+		out := util.NewPager()
+		fmt.Fprintf(out, "%3v  %-20v%6v  %v\n", "#", "Time", "Files", "Command")
+		fmt.Fprintln(out, terminal.HorizontalLine())
+		for i, op := range ops {
+			fmt.Fprintf(out, "%3v  %-20v%6v  %v\n", i+1,
+				op.Time.Local().Format("Jan 2 2006 15:04:05"), op.Files, op.Command)
+		}
+		out.Show()
+		return nil
+	}
+
+	if len(ops) == 0 {
+		return errors.New("Nothing to undo")
+	}
+	if count > len(ops) {
+		return fmt.Errorf("Only %v commands may be undone", len(ops))
+	}
+
+	for _, op := range ops[:count] {
+		err = op.Undo()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Undid", op.Command)
+	}
+
+	// The databases are out of date after the files have been restored
+	return module.RunCallback(module.Rebuild)
+}
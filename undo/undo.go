@@ -0,0 +1,267 @@
+// Package undo keeps a log of the changes made to the files in the data
+// directory, so that they may be reverted
+package undo
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nirenjan.org/overlord/config"
+	"nirenjan.org/overlord/log"
+)
+
+// Number of operations that are kept in the undo log
+const maxOperations = 50
+
+// Each invocation of Overlord that changes any files is recorded as an
+// operation in a directory under the undo log. The directory holds a
+// "command" file with the time and the command that was run, and a "files"
+// file listing each of the files that were changed. For each file that
+// existed before the change, a copy of its original contents is saved
+// alongside, otherwise the file is marked with "-" and is removed on undo.
+
+// Operation is a single recorded invocation of Overlord
+type Operation struct {
+	Time    time.Time
+	Command string
+	Files   int
+
+	dir string
+}
+
+// The operation for the current invocation, created on the first change
+var current struct {
+	dir      string
+	recorded map[string]bool
+}
+
+// logDir returns the directory holding the undo log
+func logDir() (string, error) {
+	return config.ModuleDir("undo")
+}
+
+// startOperation creates the directory for the current operation
+func startOperation() error {
+	dir, err := logDir()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	current.dir = filepath.Join(dir, fmt.Sprintf("%019d", now.UnixNano()))
+	current.recorded = make(map[string]bool)
+
+	err = os.Mkdir(current.dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	command := "overlord " + strings.Join(os.Args[1:], " ")
+	data := fmt.Sprintf("%v\n%v\n", now.Format(time.RFC3339), command)
+	err = ioutil.WriteFile(filepath.Join(current.dir, "command"), []byte(data), 0644)
+	if err != nil {
+		return err
+	}
+
+	// Failing to remove old operations only loses undo history
+	err = prune()
+	if err != nil {
+		log.Warning("Unable to prune the undo log:", err)
+	}
+
+	return nil
+}
+
+// Record saves the current contents of the file, before it is changed or
+// removed. Only the first change to each file in an invocation is recorded.
+func Record(path string) error {
+	if current.recorded[path] {
+		return nil
+	}
+
+	if current.dir == "" {
+		err := startOperation()
+		if err != nil {
+			return err
+		}
+	}
+
+	index := "-"
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		index = fmt.Sprint(len(current.recorded))
+		err = ioutil.WriteFile(filepath.Join(current.dir, index+".orig"), data, 0644)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	files, err := os.OpenFile(filepath.Join(current.dir, "files"),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer files.Close()
+
+	_, err = fmt.Fprintf(files, "%v %v\n", index, path)
+	if err != nil {
+		return err
+	}
+
+	current.recorded[path] = true
+	return nil
+}
+
+// readOperation reads the operation in the given directory
+func readOperation(dir string) (Operation, error) {
+	op := Operation{dir: dir}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "command"))
+	if err != nil {
+		return op, err
+	}
+
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	op.Time, err = time.Parse(time.RFC3339, lines[0])
+	if err != nil {
+		return op, err
+	}
+	if len(lines) == 2 {
+		op.Command = lines[1]
+	}
+
+	changes, err := op.changes()
+	op.Files = len(changes)
+	return op, err
+}
+
+// Operations returns the recorded operations, most recent first
+func Operations() ([]Operation, error) {
+	dir, err := logDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []Operation
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// Skip any operations that cannot be read, such as one that was
+		// interrupted before its command was saved. A damaged undo log
+		// must not prevent any further changes.
+		op, err := readOperation(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Debug("Skipping undo operation", entry.Name(), err)
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].dir > ops[j].dir
+	})
+
+	return ops, nil
+}
+
+// prune removes the oldest operations beyond the number that are kept
+func prune() error {
+	ops, err := Operations()
+	if err != nil {
+		return err
+	}
+
+	for len(ops) > maxOperations {
+		err = os.RemoveAll(ops[len(ops)-1].dir)
+		if err != nil {
+			return err
+		}
+		ops = ops[:len(ops)-1]
+	}
+
+	return nil
+}
+
+// change is a single file changed by an operation
+type change struct {
+	index string
+	path  string
+}
+
+// changes returns the files changed by the operation, in the order they
+// were changed
+func (op *Operation) changes() ([]change, error) {
+	f, err := os.Open(filepath.Join(op.dir, "files"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var changes []change
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("Invalid undo log entry '%v'", scanner.Text())
+		}
+		changes = append(changes, change{fields[0], fields[1]})
+	}
+
+	return changes, scanner.Err()
+}
+
+// Undo restores the files changed by the operation to their original
+// contents, and removes the operation from the log
+func (op *Operation) Undo() error {
+	changes, err := op.changes()
+	if err != nil {
+		return err
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.index == "-" {
+			err = os.Remove(c.path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+
+		var data []byte
+		data, err = ioutil.ReadFile(filepath.Join(op.dir, c.index+".orig"))
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(filepath.Dir(c.path), os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(c.path, data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(op.dir)
+}
@@ -0,0 +1,202 @@
+package undo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tempDataDir points the data directory at a new temporary directory, and
+// starts a fresh operation. It returns the directory, and a function that
+// restores the environment and removes the directory.
+func tempDataDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "overlord-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, set := os.LookupEnv("OVERLORD_DATA")
+	os.Setenv("OVERLORD_DATA", dir)
+	newOperation()
+
+	return dir, func() {
+		if set {
+			os.Setenv("OVERLORD_DATA", old)
+		} else {
+			os.Unsetenv("OVERLORD_DATA")
+		}
+		os.RemoveAll(dir)
+		newOperation()
+	}
+}
+
+// newOperation makes the next change start a new operation, as if it was
+// made by a separate invocation. Operations are named by the time they were
+// started, so wait long enough for the name to differ.
+func newOperation() {
+	current.dir = ""
+	current.recorded = nil
+	time.Sleep(time.Millisecond)
+}
+
+// writeFile records the file and then writes the given contents to it
+func writeFile(t *testing.T, path, contents string) {
+	err := Record(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(path, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkFile verifies the contents of the file
+func checkFile(t *testing.T, path, exp string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("Expected '%v' in %v, got error %v\n", exp, path, err)
+	} else if string(data) != exp {
+		t.Errorf("Expected '%v' in %v, got '%v'\n", exp, path, string(data))
+	}
+}
+
+// TestStrayOperation verifies that a damaged operation in the undo log does
+// not prevent recording and undoing changes
+func TestStrayOperation(t *testing.T) {
+	data, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	dir, err := logDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An operation that was interrupted before the command was saved
+	err = os.Mkdir(filepath.Join(dir, "stray"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(data, "file")
+	err = ioutil.WriteFile(path, []byte("before"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, path, "after")
+
+	ops, err := Operations()
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("Expected 1 operation, got %v (%v)\n", len(ops), err)
+	}
+
+	err = ops[0].Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkFile(t, path, "before")
+}
+
+// TestUndoNewFile verifies that undoing the creation of a file removes it,
+// and restores any other files changed by the same operation
+func TestUndoNewFile(t *testing.T) {
+	data, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	existing := filepath.Join(data, "existing")
+	err := ioutil.WriteFile(existing, []byte("before"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := filepath.Join(data, "created")
+	writeFile(t, created, "new")
+	writeFile(t, existing, "after")
+
+	ops, err := Operations()
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("Expected 1 operation, got %v (%v)\n", len(ops), err)
+	}
+	if ops[0].Files != 2 {
+		t.Errorf("Expected 2 files changed, got %v\n", ops[0].Files)
+	}
+
+	err = ops[0].Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("Expected %v to be removed, got %v\n", created, err)
+	}
+	checkFile(t, existing, "before")
+
+	ops, err = Operations()
+	if err != nil || len(ops) != 0 {
+		t.Errorf("Expected the operation to be removed, got %v (%v)\n", len(ops), err)
+	}
+}
+
+// TestUndoCount verifies that undo -n reverts the most recent operations,
+// and leaves the older ones in the log
+func TestUndoCount(t *testing.T) {
+	data, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	path := filepath.Join(data, "file")
+	for _, contents := range []string{"one", "two", "three", "four"} {
+		writeFile(t, path, contents)
+		newOperation()
+	}
+
+	err := undoHandler(nil, []string{"undo", "-n", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkFile(t, path, "two")
+
+	ops, err := Operations()
+	if err != nil || len(ops) != 2 {
+		t.Fatalf("Expected 2 operations, got %v (%v)\n", len(ops), err)
+	}
+
+	err = undoHandler(nil, []string{"undo", "-n", "3"})
+	if err == nil {
+		t.Errorf("Expected an error undoing more operations than recorded\n")
+	}
+	checkFile(t, path, "two")
+}
+
+// TestPrune verifies that only the most recent operations are kept
+func TestPrune(t *testing.T) {
+	data, cleanup := tempDataDir(t)
+	defer cleanup()
+
+	var dirs []string
+	path := filepath.Join(data, "file")
+	for i := 0; i < maxOperations+5; i++ {
+		writeFile(t, path, "contents")
+		dirs = append(dirs, current.dir)
+		newOperation()
+	}
+
+	ops, err := Operations()
+	if err != nil || len(ops) != maxOperations {
+		t.Fatalf("Expected %v operations, got %v (%v)\n", maxOperations, len(ops), err)
+	}
+
+	for i, dir := range dirs {
+		_, err = os.Stat(dir)
+		if i < 5 && !os.IsNotExist(err) {
+			t.Errorf("Expected operation %v to be pruned, got %v\n", i, err)
+		} else if i >= 5 && err != nil {
+			t.Errorf("Expected operation %v to be kept, got %v\n", i, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the absolute date formats accepted by ParseDate, and
+// whether they include a time of day
+var dateLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{"2006-01-02", false},
+	{"2006-01-02T15:04", true},
+	{"2006-01-02T15:04:05", true},
+	{time.RFC3339, true},
+	{"15:04", true},
+}
+
+// midnight returns the start of the day for the given time
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last second of the day for the given time
+func EndOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// parseWeekday returns the weekday for a full or abbreviated weekday name
+func parseWeekday(s string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+
+	return time.Sunday, false
+}
+
+// parseOffset parses a relative offset such as +3d or -2w
+func parseOffset(s string, now time.Time) (time.Time, bool, error) {
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return now, false, err
+	}
+
+	today := midnight(now)
+	switch s[len(s)-1] {
+	case 'h':
+		return now.Add(time.Duration(n) * time.Hour), true, nil
+	case 'd':
+		return today.AddDate(0, 0, n), false, nil
+	case 'w':
+		return today.AddDate(0, 0, 7*n), false, nil
+	case 'm':
+		return today.AddDate(0, n, 0), false, nil
+	case 'y':
+		return today.AddDate(n, 0, 0), false, nil
+	}
+
+	return now, false, fmt.Errorf("Invalid unit in '%v'", s)
+}
+
+// ParseDate parses an absolute or relative date, relative to the given time.
+// It returns the parsed time, and whether the input included a time of day.
+// Dates without a time of day are returned at midnight, local time.
+//
+// The following forms are accepted
+//
+//	2026-11-05              a date
+//	2026-11-05T14:00        a date and time
+//	14:00                   a time today
+//	now                     the current time
+//	today, tomorrow, yesterday
+//	friday, fri             the next Friday, or today if it is a Friday
+//	next friday             the next Friday after today
+//	+3d, +2w, +1m, +1y      days, weeks, months or years from today
+//	+4h                     hours from now
+//	-3d                     days ago, and similarly for the other units
+//	eow, eom, eoy           the end of the week (Sunday), month or year
+func ParseDate(s string, now time.Time) (time.Time, bool, error) {
+	orig := strings.TrimSpace(s)
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	today := midnight(now)
+
+	switch s {
+	case "now":
+		return now, true, nil
+
+	case "today":
+		return today, false, nil
+
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), false, nil
+
+	case "yesterday":
+		return today.AddDate(0, 0, -1), false, nil
+
+	case "eow":
+		days := (7 - int(today.Weekday())) % 7
+		return today.AddDate(0, 0, days), false, nil
+
+	case "eom":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0,
+			today.Location()), false, nil
+
+	case "eoy":
+		return time.Date(today.Year(), time.December, 31, 0, 0, 0, 0,
+			today.Location()), false, nil
+	}
+
+	// Weekdays
+	next := strings.HasPrefix(s, "next ")
+	if day, ok := parseWeekday(strings.TrimPrefix(s, "next ")); ok {
+		days := (int(day) - int(today.Weekday()) + 7) % 7
+		if next && days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), false, nil
+	}
+
+	// Relative offsets
+	if len(s) > 2 && (s[0] == '+' || s[0] == '-') {
+		t, hasTime, err := parseOffset(s, now)
+		if err == nil {
+			return t, hasTime, nil
+		}
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout.layout, orig, time.Local)
+		if err == nil {
+			if layout.layout == "15:04" {
+				t = time.Date(today.Year(), today.Month(), today.Day(),
+					t.Hour(), t.Minute(), 0, 0, today.Location())
+			}
+			return t, layout.hasTime, nil
+		}
+	}
+
+	return now, false, fmt.Errorf("Invalid date '%v'", orig)
+}
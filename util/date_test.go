@@ -0,0 +1,63 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseDate verifies the absolute and relative date formats
+func TestParseDate(t *testing.T) {
+	// Saturday, October 17 2026, 10:30 AM
+	now := time.Date(2026, 10, 17, 10, 30, 0, 0, time.Local)
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		in      string
+		exp     time.Time
+		hasTime bool
+	}{
+		{"2026-11-05", date(2026, 11, 5), false},
+		{"2026-11-05T14:00", time.Date(2026, 11, 5, 14, 0, 0, 0, time.Local), true},
+		{"2026-11-05T14:00:30", time.Date(2026, 11, 5, 14, 0, 30, 0, time.Local), true},
+		{"15:00", time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local), true},
+		{"now", now, true},
+		{"today", date(2026, 10, 17), false},
+		{"Tomorrow", date(2026, 10, 18), false},
+		{"yesterday", date(2026, 10, 16), false},
+		{"friday", date(2026, 10, 23), false},
+		{"sat", date(2026, 10, 17), false},
+		{"next saturday", date(2026, 10, 24), false},
+		{"next  monday", date(2026, 10, 19), false},
+		{"+3d", date(2026, 10, 20), false},
+		{"+2w", date(2026, 10, 31), false},
+		{"+1m", date(2026, 11, 17), false},
+		{"+1y", date(2027, 10, 17), false},
+		{"-3d", date(2026, 10, 14), false},
+		{"+4h", time.Date(2026, 10, 17, 14, 30, 0, 0, time.Local), true},
+		{"eow", date(2026, 10, 18), false},
+		{"eom", date(2026, 10, 31), false},
+		{"eoy", date(2026, 12, 31), false},
+	}
+
+	for _, test := range tests {
+		d, hasTime, err := ParseDate(test.in, now)
+		if err != nil {
+			t.Errorf("Parsing '%v', got error %v\n", test.in, err)
+			continue
+		}
+
+		if !d.Equal(test.exp) || hasTime != test.hasTime {
+			t.Errorf("Parsing '%v', expected %v (%v), got %v (%v)\n", test.in,
+				test.exp, test.hasTime, d, hasTime)
+		}
+	}
+
+	for _, in := range []string{"", "someday", "+3x", "next", "2026-13-01"} {
+		_, _, err := ParseDate(in, now)
+		if err == nil {
+			t.Errorf("Parsing '%v', expected an error\n", in)
+		}
+	}
+}